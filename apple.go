@@ -0,0 +1,345 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "errors"
+
+// This file implements the private tags found in display profiles
+// generated by macOS.  The data layout follows the CMICCProfile.h header
+// from Apple's ColorSync framework.
+
+// MakeAndModel identifies the device a profile was created for.
+type MakeAndModel struct {
+	Manufacturer    uint32
+	Model           uint32
+	SerialNumber    uint32
+	ManufactureDate uint32
+}
+
+func decodeMakeAndModel(data []byte) (*MakeAndModel, error) {
+	err := checkType("mmod", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 24 {
+		return nil, errInvalidTagData
+	}
+	res := &MakeAndModel{
+		Manufacturer:    getUint32(data, 8),
+		Model:           getUint32(data, 12),
+		SerialNumber:    getUint32(data, 16),
+		ManufactureDate: getUint32(data, 20),
+	}
+	return res, nil
+}
+
+func (m *MakeAndModel) encode() []byte {
+	buf := make([]byte, 40)
+	copy(buf, "mmod")
+	putUint32(buf, 8, m.Manufacturer)
+	putUint32(buf, 12, m.Model)
+	putUint32(buf, 16, m.SerialNumber)
+	putUint32(buf, 20, m.ManufactureDate)
+	return buf
+}
+
+// MakeAndModel returns the contents of the Apple make and model tag.
+func (p *Profile) MakeAndModel() (*MakeAndModel, error) {
+	tag, ok := p.TagData[MakeAndModelTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeMakeAndModel(tag)
+}
+
+// SetMakeAndModel sets the Apple make and model tag.
+func (p *Profile) SetMakeAndModel(m *MakeAndModel) {
+	p.SetTag(MakeAndModelTag, m.encode())
+}
+
+// NativeDisplayInfo describes the native, uncalibrated response of a display.
+type NativeDisplayInfo struct {
+	// Red, Green, Blue and White give the xy chromaticities of the display
+	// primaries and of the native white point.
+	Red, Green, Blue, White [2]float64
+
+	// Gamma gives the native gamma values for the red, green and blue
+	// channels.
+	Gamma [3]float64
+
+	// GammaTable, if non-empty, contains one gamma ramp per channel.
+	// The values range from 0 to 255 if GammaEntrySize is 1, and from
+	// 0 to 65535 if GammaEntrySize is 2.
+	GammaTable     [][]uint16
+	GammaEntrySize int
+}
+
+func decodeNativeDisplayInfo(data []byte) (*NativeDisplayInfo, error) {
+	err := checkType("ndin", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 62 {
+		return nil, errInvalidTagData
+	}
+	res := &NativeDisplayInfo{}
+	for i, xy := range []*[2]float64{&res.Red, &res.Green, &res.Blue, &res.White} {
+		xy[0] = getS15Fixed16(data, 12+8*i)
+		xy[1] = getS15Fixed16(data, 16+8*i)
+	}
+	for i := range res.Gamma {
+		res.Gamma[i] = getS15Fixed16(data, 44+4*i)
+	}
+	table, entrySize, err := decodeGammaTable(data, 56)
+	if err != nil {
+		return nil, err
+	}
+	res.GammaTable = table
+	res.GammaEntrySize = entrySize
+	return res, nil
+}
+
+func (n *NativeDisplayInfo) encode() ([]byte, error) {
+	buf := make([]byte, 56)
+	copy(buf, "ndin")
+	for i, xy := range [][2]float64{n.Red, n.Green, n.Blue, n.White} {
+		putS15Fixed16(buf, 12+8*i, xy[0])
+		putS15Fixed16(buf, 16+8*i, xy[1])
+	}
+	for i, g := range n.Gamma {
+		putS15Fixed16(buf, 44+4*i, g)
+	}
+	buf, err := appendGammaTable(buf, n.GammaTable, n.GammaEntrySize)
+	if err != nil {
+		return nil, err
+	}
+	putUint32(buf, 8, uint32(len(buf)-8))
+	return buf, nil
+}
+
+// NativeDisplayInfo returns the contents of the Apple native display
+// information tag.
+func (p *Profile) NativeDisplayInfo() (*NativeDisplayInfo, error) {
+	tag, ok := p.TagData[NativeDisplayInfoTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeNativeDisplayInfo(tag)
+}
+
+// SetNativeDisplayInfo sets the Apple native display information tag.
+// An error is returned if the gamma table cannot be represented.
+func (p *Profile) SetNativeDisplayInfo(n *NativeDisplayInfo) error {
+	data, err := n.encode()
+	if err != nil {
+		return err
+	}
+	p.SetTag(NativeDisplayInfoTag, data)
+	return nil
+}
+
+// VideoCardGamma contains the calibration curves which are loaded into the
+// video card lookup table when the profile is activated.
+// If Table is empty, the curves are given by Formula.
+type VideoCardGamma struct {
+	// Table contains one lookup table per channel.  The values range from
+	// 0 to 255 if EntrySize is 1, and from 0 to 65535 if EntrySize is 2.
+	Table     [][]uint16
+	EntrySize int
+
+	// Formula gives the parameters for the red, green and blue channels.
+	Formula [3]VideoCardGammaFormula
+}
+
+// VideoCardGammaFormula describes the curve
+// y = Min + (Max - Min) * x^Gamma for one channel.
+type VideoCardGammaFormula struct {
+	Gamma, Min, Max float64
+}
+
+func decodeVideoCardGamma(data []byte) (*VideoCardGamma, error) {
+	err := checkType("vcgt", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 {
+		return nil, errInvalidTagData
+	}
+	res := &VideoCardGamma{}
+	switch getUint32(data, 8) {
+	case 0:
+		table, entrySize, err := decodeGammaTable(data, 12)
+		if err != nil {
+			return nil, err
+		}
+		if len(table) == 0 {
+			return nil, errInvalidTagData
+		}
+		res.Table = table
+		res.EntrySize = entrySize
+	case 1:
+		if len(data) < 48 {
+			return nil, errInvalidTagData
+		}
+		for i := range res.Formula {
+			res.Formula[i] = VideoCardGammaFormula{
+				Gamma: getS15Fixed16(data, 12+12*i),
+				Min:   getS15Fixed16(data, 16+12*i),
+				Max:   getS15Fixed16(data, 20+12*i),
+			}
+		}
+	default:
+		return nil, errInvalidTagData
+	}
+	return res, nil
+}
+
+func (v *VideoCardGamma) encode() ([]byte, error) {
+	buf := make([]byte, 12)
+	copy(buf, "vcgt")
+	if len(v.Table) > 0 {
+		return appendGammaTable(buf, v.Table, v.EntrySize)
+	}
+
+	putUint32(buf, 8, 1)
+	buf = append(buf, make([]byte, 36)...)
+	for i, f := range v.Formula {
+		putS15Fixed16(buf, 12+12*i, f.Gamma)
+		putS15Fixed16(buf, 16+12*i, f.Min)
+		putS15Fixed16(buf, 20+12*i, f.Max)
+	}
+	return buf, nil
+}
+
+// VideoCardGamma returns the contents of the Apple video card gamma tag.
+func (p *Profile) VideoCardGamma() (*VideoCardGamma, error) {
+	tag, ok := p.TagData[VideoCardGammaTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeVideoCardGamma(tag)
+}
+
+// SetVideoCardGamma sets the Apple video card gamma tag.
+// An error is returned if the table cannot be represented.
+func (p *Profile) SetVideoCardGamma(v *VideoCardGamma) error {
+	data, err := v.encode()
+	if err != nil {
+		return err
+	}
+	p.SetTag(VideoCardGammaTag, data)
+	return nil
+}
+
+// maxGammaChannels is the largest number of channels accepted in a gamma
+// table.  Tables in practice have either one channel or one channel each
+// for red, green and blue.
+const maxGammaChannels = 3
+
+// decodeGammaTable reads a gamma table consisting of the channel count,
+// entry count and entry size, followed by the table data.  A table with
+// zero channels is returned as nil.  Otherwise, the table must have at most
+// maxGammaChannels channels and at least one entry per channel.
+func decodeGammaTable(data []byte, offset int) ([][]uint16, int, error) {
+	if len(data) < offset+6 {
+		return nil, 0, errInvalidTagData
+	}
+	channels := int(getUint16(data, offset))
+	entryCount := int(getUint16(data, offset+2))
+	entrySize := int(getUint16(data, offset+4))
+	if channels == 0 {
+		return nil, 0, nil
+	}
+	if channels > maxGammaChannels || entryCount == 0 {
+		return nil, 0, errInvalidTagData
+	}
+	if entrySize != 1 && entrySize != 2 {
+		return nil, 0, errInvalidTagData
+	}
+	pos := offset + 6
+	if uint64(len(data)-pos) < uint64(channels)*uint64(entryCount)*uint64(entrySize) {
+		return nil, 0, errInvalidTagData
+	}
+
+	table := make([][]uint16, channels)
+	for i := range table {
+		table[i] = make([]uint16, entryCount)
+		for j := range table[i] {
+			if entrySize == 1 {
+				table[i][j] = uint16(data[pos])
+			} else {
+				table[i][j] = getUint16(data, pos)
+			}
+			pos += entrySize
+		}
+	}
+	return table, entrySize, nil
+}
+
+// appendGammaTable appends a gamma table in the format read by
+// decodeGammaTable.  An entry size of 0 is treated as 2.  An error is
+// returned if there are more than maxGammaChannels channels, if the
+// channels are empty or differ in length, or if the values do not fit into
+// the entry size.
+func appendGammaTable(buf []byte, table [][]uint16, entrySize int) ([]byte, error) {
+	switch entrySize {
+	case 0:
+		entrySize = 2
+	case 1, 2:
+		// pass
+	default:
+		return nil, errInvalidGammaTable
+	}
+	entryCount := 0
+	if len(table) > 0 {
+		entryCount = len(table[0])
+	}
+	if len(table) > maxGammaChannels || entryCount > 0xFFFF {
+		return nil, errInvalidGammaTable
+	}
+	if len(table) > 0 && entryCount == 0 {
+		return nil, errInvalidGammaTable
+	}
+	for _, channel := range table {
+		if len(channel) != entryCount {
+			return nil, errInvalidGammaTable
+		}
+	}
+
+	buf = append(buf,
+		byte(len(table)>>8), byte(len(table)),
+		byte(entryCount>>8), byte(entryCount),
+		0, byte(entrySize))
+	for _, channel := range table {
+		for _, x := range channel {
+			if entrySize == 1 {
+				if x > 0xFF {
+					return nil, errInvalidGammaTable
+				}
+				buf = append(buf, byte(x))
+			} else {
+				buf = append(buf, byte(x>>8), byte(x))
+			}
+		}
+	}
+	return buf, nil
+}
+
+var errInvalidGammaTable = errors.New("invalid gamma table")
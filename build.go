@@ -0,0 +1,272 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Chromaticity is a pair of CIE xy chromaticity coordinates.
+type Chromaticity [2]float64
+
+// XYZ returns the XYZ values of the color with chromaticity c and
+// luminance Y = 1.  The y coordinate must be positive.
+func (c Chromaticity) XYZ() XYZ {
+	x, y := c[0], c[1]
+	return XYZ{x / y, 1, (1 - x - y) / y}
+}
+
+// valid reports whether both coordinates are finite and y is positive.
+func (c Chromaticity) valid() bool {
+	return isFinite(c[0]) && isFinite(c[1]) && c[1] > 0
+}
+
+// Chromaticities gives the chromaticities of the primaries of an RGB
+// color space.
+type Chromaticities struct {
+	Red, Green, Blue Chromaticity
+}
+
+// SRGBPrimaries are the primaries of the sRGB color space, as defined in
+// IEC 61966-2-1.
+var SRGBPrimaries = Chromaticities{
+	Red:   Chromaticity{0.64, 0.33},
+	Green: Chromaticity{0.30, 0.60},
+	Blue:  Chromaticity{0.15, 0.06},
+}
+
+// ProfileOptions gives optional metadata for the profile constructors.
+type ProfileOptions struct {
+	// Description is stored in the profile description tag.  If this is
+	// empty, a generic description is used.
+	Description string
+
+	// Copyright is stored in the copyright tag.  If this is empty, a
+	// placeholder is used.
+	Copyright string
+
+	// CreationDate is stored in the profile header.  If this is zero, the
+	// current time is used.
+	CreationDate time.Time
+
+	// Class is the profile class.  If this is zero, a display profile is
+	// created.  Each constructor documents which classes it supports.
+	Class ProfileClass
+}
+
+// NewRGBProfile creates a version 4 profile for an RGB device described by
+// a 3x3 matrix and a tone reproduction curve.  The white point gives the
+// XYZ values of the device white, with luminance Y = 1.  The curve maps
+// device values to linear light and is used for all three channels.  If
+// opt is nil, default options are used.  The profile class can be
+// DisplayDeviceProfile (the default) or InputDeviceProfile.
+//
+// The colorants in the profile are adapted to the PCS illuminant using the
+// linear Bradford transform, which is recorded in the chromatic adaptation
+// tag.
+func NewRGBProfile(primaries Chromaticities, white XYZ, trc *Curve, opt *ProfileOptions) (*Profile, error) {
+	class, err := profileClass(opt, DisplayDeviceProfile, InputDeviceProfile)
+	if err != nil {
+		return nil, err
+	}
+	if trc == nil {
+		return nil, errInvalidCurve
+	} else if err := trc.check(); err != nil {
+		return nil, err
+	}
+	if !validWhitePoint(white) {
+		return nil, errInvalidWhitePoint
+	}
+	if !primaries.Red.valid() || !primaries.Green.valid() || !primaries.Blue.valid() {
+		return nil, errInvalidChromaticity
+	}
+
+	// Compute the matrix which maps linear RGB values to XYZ values,
+	// relative to the device white.
+	r := primaries.Red.XYZ()
+	g := primaries.Green.XYZ()
+	b := primaries.Blue.XYZ()
+	m := &matrix3{
+		r[0], g[0], b[0],
+		r[1], g[1], b[1],
+		r[2], g[2], b[2],
+	}
+	mInv := m.inv()
+	if mInv == nil {
+		return nil, errInvalidPrimaries
+	}
+	s := mInv.apply(white)
+	for i := 0; i < 3; i++ {
+		m[3*i] *= s[0]
+		m[3*i+1] *= s[1]
+		m[3*i+2] *= s[2]
+	}
+
+	chad := bradford(white, PCSIlluminant)
+	if chad == nil {
+		return nil, errInvalidWhitePoint
+	}
+	m = chad.mul(m)
+	for _, x := range m {
+		if !isFinite(x) {
+			return nil, errInvalidPrimaries
+		}
+	}
+
+	p := newProfile(class, RGBSpace, opt, "RGB Profile")
+	p.SetWhitePoint(PCSIlluminant)
+	p.SetTag(ChromaticAdaption, encodeMatrix(chad))
+	p.SetTag(RedColorant, encodeXYZ(XYZ{m[0], m[3], m[6]}))
+	p.SetTag(GreenColorant, encodeXYZ(XYZ{m[1], m[4], m[7]}))
+	p.SetTag(BlueColorant, encodeXYZ(XYZ{m[2], m[5], m[8]}))
+	curve := trc.encode()
+	p.SetTag(RedTRC, curve)
+	p.SetTag(GreenTRC, curve)
+	p.SetTag(BlueTRC, curve)
+	return p, nil
+}
+
+// NewGrayProfile creates a version 4 profile for a monochrome device.  The
+// white point gives the XYZ values of the device white, with luminance
+// Y = 1.  The curve maps device values to the luminance of the output.
+// Use GammaCurve to describe a device by a single gamma value.  If opt is
+// nil, default options are used.  The profile class can be
+// DisplayDeviceProfile (the default), InputDeviceProfile or
+// OutputDeviceProfile.  Output profiles are needed, for example, for
+// gray output intents in PDF files.
+func NewGrayProfile(white XYZ, trc *Curve, opt *ProfileOptions) (*Profile, error) {
+	class, err := profileClass(opt, DisplayDeviceProfile, InputDeviceProfile, OutputDeviceProfile)
+	if err != nil {
+		return nil, err
+	}
+	if trc == nil {
+		return nil, errInvalidCurve
+	} else if err := trc.check(); err != nil {
+		return nil, err
+	}
+	if !validWhitePoint(white) {
+		return nil, errInvalidWhitePoint
+	}
+	chad := bradford(white, PCSIlluminant)
+	if chad == nil {
+		return nil, errInvalidWhitePoint
+	}
+
+	p := newProfile(class, GraySpace, opt, "Gray Profile")
+	p.SetWhitePoint(PCSIlluminant)
+	p.SetTag(ChromaticAdaption, encodeMatrix(chad))
+	p.SetTag(GrayTRC, trc.encode())
+	return p, nil
+}
+
+// profileClass returns the profile class selected in opt.  If no class is
+// selected, the first of the allowed classes is returned.  An error is
+// returned if the selected class is not allowed.
+func profileClass(opt *ProfileOptions, allowed ...ProfileClass) (ProfileClass, error) {
+	if opt == nil || opt.Class == 0 {
+		return allowed[0], nil
+	}
+	if !slices.Contains(allowed, opt.Class) {
+		return 0, fmt.Errorf("icc: unsupported profile class %s", opt.Class)
+	}
+	return opt.Class, nil
+}
+
+// newProfile creates an empty version 4 profile with the PCSXYZ
+// connection space and the description and copyright tags set.
+func newProfile(class ProfileClass, space ColorSpace, opt *ProfileOptions, defaultDesc string) *Profile {
+	if opt == nil {
+		opt = &ProfileOptions{}
+	}
+
+	date := opt.CreationDate
+	if date.IsZero() {
+		date = time.Now().UTC().Truncate(time.Second)
+	}
+
+	p := &Profile{
+		Version:      currentVersion,
+		Class:        class,
+		ColorSpace:   space,
+		PCS:          PCSXYZSpace,
+		CreationDate: date,
+		TagData:      make(map[TagType][]byte),
+	}
+
+	desc := opt.Description
+	if desc == "" {
+		desc = defaultDesc
+	}
+	p.SetDescription(desc)
+	cprt := opt.Copyright
+	if cprt == "" {
+		cprt = "No copyright information available"
+	}
+	p.SetCopyright(cprt)
+	return p
+}
+
+// validWhitePoint reports whether all components of white are finite and
+// the luminance is positive.
+func validWhitePoint(white XYZ) bool {
+	return isFinite(white[0]) && isFinite(white[1]) && isFinite(white[2]) &&
+		white[1] > 0
+}
+
+// bradford returns the linear Bradford chromatic adaptation matrix from
+// the white point src to the white point dst.  If the adaptation is not
+// defined, nil is returned.
+func bradford(src, dst XYZ) *matrix3 {
+	coneSrc := bradfordCone.apply(src)
+	coneDst := bradfordCone.apply(dst)
+	scale := &matrix3{}
+	for i := 0; i < 3; i++ {
+		if coneSrc[i] == 0 {
+			return nil
+		}
+		scale[4*i] = coneDst[i] / coneSrc[i]
+	}
+	return bradfordConeInv.mul(scale).mul(bradfordCone)
+}
+
+var bradfordCone = &matrix3{
+	0.8951, 0.2664, -0.1614,
+	-0.7502, 1.7135, 0.0367,
+	0.0389, -0.0685, 1.0296,
+}
+
+var bradfordConeInv = bradfordCone.inv()
+
+// encodeMatrix encodes m as a s15Fixed16ArrayType element, in row-major
+// order.  This is the format of the chromatic adaptation tag.
+func encodeMatrix(m *matrix3) []byte {
+	buf := make([]byte, 8+4*len(m))
+	copy(buf, "sf32")
+	for i, x := range m {
+		putS15Fixed16(buf, 8+4*i, x)
+	}
+	return buf
+}
+
+var (
+	errInvalidChromaticity = errors.New("invalid chromaticity")
+	errInvalidPrimaries    = errors.New("primaries are linearly dependent")
+	errInvalidWhitePoint   = errors.New("invalid white point")
+)
@@ -0,0 +1,256 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestNewRGBProfile(t *testing.T) {
+	white := WhitePoint(IlluminantD65, Observer2)
+	p, err := NewRGBProfile(SRGBPrimaries, white, SRGBCurve(), &ProfileOptions{
+		Description: "sRGB test",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Class != DisplayDeviceProfile || q.ColorSpace != RGBSpace || q.PCS != PCSXYZSpace {
+		t.Errorf("wrong header: %s %s %s", q.Class, q.ColorSpace, q.PCSName())
+	}
+	if err := q.Validate(); err != nil {
+		t.Error(err)
+	}
+	if q.CreationDate.IsZero() {
+		t.Error("missing creation date")
+	}
+	desc, err := q.Description()
+	if err != nil || desc.Lookup("en", "US") != "sRGB test" {
+		t.Errorf("wrong description %v %v", desc, err)
+	}
+
+	// The colorants should match the ones from the ICC sRGB profile.
+	var sum XYZ
+	for i, tag := range []TagType{RedColorant, GreenColorant, BlueColorant} {
+		c, err := decodeXYZ(q.TagData[tag])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for j := range c {
+			sum[j] += c[j]
+			if math.Abs(c[j]-srgbToPCS[3*j+i]) > 1e-3 {
+				t.Errorf("%s: got %v", tag, c)
+			}
+		}
+	}
+	for j := range sum {
+		if math.Abs(sum[j]-PCSIlluminant[j]) > 2e-4 {
+			t.Errorf("colorants add up to %v, not %v", sum, PCSIlluminant)
+			break
+		}
+	}
+
+	wtpt, err := q.WhitePoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for j := range wtpt {
+		if !approxEqual(wtpt[j], PCSIlluminant[j]) {
+			t.Errorf("wrong white point %v", wtpt)
+			break
+		}
+	}
+
+	trc, err := decodeCurve(q.TagData[GreenTRC])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, x := range []float64{0, 0.02, 0.5, 1} {
+		if y := trc.Eval(x); math.Abs(y-srgbLinear(x)) > 1e-4 {
+			t.Errorf("TRC(%g) = %g, want %g", x, y, srgbLinear(x))
+		}
+	}
+}
+
+func TestNewRGBProfileDate(t *testing.T) {
+	date := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	p, err := NewRGBProfile(SRGBPrimaries, PCSIlluminant, GammaCurve(2.2), &ProfileOptions{
+		CreationDate: date,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.CreationDate.Equal(date) {
+		t.Errorf("got creation date %s, want %s", q.CreationDate, date)
+	}
+}
+
+func TestNewRGBProfileErrors(t *testing.T) {
+	white := WhitePoint(IlluminantD65, Observer2)
+	withRed := func(c Chromaticity) Chromaticities {
+		res := SRGBPrimaries
+		res.Red = c
+		return res
+	}
+	nan := math.NaN()
+	inf := math.Inf(1)
+	for _, test := range []struct {
+		name      string
+		primaries Chromaticities
+		white     XYZ
+		trc       *Curve
+		want      error
+	}{
+		{"degenerate primaries", Chromaticities{
+			Red:   Chromaticity{0.3, 0.3},
+			Green: Chromaticity{0.3, 0.3},
+			Blue:  Chromaticity{0.15, 0.06},
+		}, white, SRGBCurve(), errInvalidPrimaries},
+		{"y = 0", withRed(Chromaticity{0.64, 0}), white, SRGBCurve(), errInvalidChromaticity},
+		{"y < 0", withRed(Chromaticity{0.64, -0.1}), white, SRGBCurve(), errInvalidChromaticity},
+		{"x = NaN", withRed(Chromaticity{nan, 0.33}), white, SRGBCurve(), errInvalidChromaticity},
+		{"x = Inf", withRed(Chromaticity{inf, 0.33}), white, SRGBCurve(), errInvalidChromaticity},
+		{"y = Inf", withRed(Chromaticity{0.64, inf}), white, SRGBCurve(), errInvalidChromaticity},
+		{"tiny y", withRed(Chromaticity{0.64, 1e-320}), white, SRGBCurve(), errInvalidPrimaries},
+		{"zero white", SRGBPrimaries, XYZ{}, GammaCurve(2.2), errInvalidWhitePoint},
+		{"infinite white", SRGBPrimaries, XYZ{inf, 1, 1}, GammaCurve(2.2), errInvalidWhitePoint},
+		{"NaN white", SRGBPrimaries, XYZ{0.95, nan, 1.09}, GammaCurve(2.2), errInvalidWhitePoint},
+		{"missing curve", SRGBPrimaries, white, nil, errInvalidCurve},
+		{"short curve", SRGBPrimaries, white, &Curve{FunctionType: 3}, errInvalidCurve},
+		{"NaN gamma", SRGBPrimaries, white, GammaCurve(nan), errInvalidCurve},
+		{"negative gamma", SRGBPrimaries, white, GammaCurve(-2.2), errInvalidCurve},
+	} {
+		_, err := NewRGBProfile(test.primaries, test.white, test.trc, nil)
+		if err != test.want {
+			t.Errorf("%s: got %v, want %v", test.name, err, test.want)
+		}
+	}
+}
+
+func TestNewGrayProfile(t *testing.T) {
+	p, err := NewGrayProfile(WhitePoint(IlluminantD65, Observer2), GammaCurve(2.2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Class != DisplayDeviceProfile || q.ColorSpace != GraySpace {
+		t.Errorf("wrong header: %s %s", q.Class, q.ColorSpace)
+	}
+	for _, tag := range []TagType{ProfileDescription, Copyright, MediaWhitePoint, ChromaticAdaption, GrayTRC} {
+		if _, ok := q.TagData[tag]; !ok {
+			t.Errorf("missing tag %s", tag)
+		}
+	}
+	trc, err := decodeCurve(q.TagData[GrayTRC])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if y := trc.Eval(0.5); math.Abs(y-math.Pow(0.5, 2.2)) > 1e-4 {
+		t.Errorf("TRC(0.5) = %g", y)
+	}
+
+	if _, err := NewGrayProfile(PCSIlluminant, nil, nil); err == nil {
+		t.Error("missing curve not detected")
+	}
+	if _, err := NewGrayProfile(XYZ{math.Inf(1), 1, 1}, GammaCurve(2.2), nil); err != errInvalidWhitePoint {
+		t.Errorf("infinite white point: got %v", err)
+	}
+}
+
+func TestProfileClass(t *testing.T) {
+	opt := &ProfileOptions{Class: OutputDeviceProfile}
+	p, err := NewGrayProfile(PCSIlluminant, GammaCurve(2.2), opt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.Class != OutputDeviceProfile {
+		t.Errorf("got class %s", q.Class)
+	}
+	if err := q.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// matrix/TRC profiles cannot be output profiles
+	_, err = NewRGBProfile(SRGBPrimaries, WhitePoint(IlluminantD65, Observer2), SRGBCurve(), opt)
+	if err == nil {
+		t.Error("output class accepted for RGB profile")
+	}
+	_, err = NewGrayProfile(PCSIlluminant, GammaCurve(2.2), &ProfileOptions{Class: DeviceLinkProfile})
+	if err == nil {
+		t.Error("device link class accepted for gray profile")
+	}
+}
+
+func TestCurveRoundTrip(t *testing.T) {
+	for _, c := range []*Curve{
+		GammaCurve(1.8),
+		{FunctionType: 4, Params: []float64{2.2, 0.9, 0.1, 0.05, 0.1, 0.01, 0.002}},
+	} {
+		c2, err := decodeCurve(c.encode())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, x := range []float64{0, 0.05, 0.3, 1} {
+			if d := math.Abs(c.Eval(x) - c2.Eval(x)); d > 1e-4 {
+				t.Errorf("type %d: mismatch at %g", c.FunctionType, x)
+			}
+		}
+	}
+}
+
+func TestCurveInvalid(t *testing.T) {
+	for _, c := range []*Curve{
+		{FunctionType: 5, Params: []float64{1}},
+		{FunctionType: -1, Params: []float64{1}},
+		{FunctionType: 3, Params: []float64{2.2}},
+		{FunctionType: 0},
+		GammaCurve(math.NaN()),
+		GammaCurve(math.Inf(1)),
+		GammaCurve(-1),
+		GammaCurve(0),
+		{FunctionType: 1, Params: []float64{2.2, math.NaN(), 0}},
+	} {
+		if c.check() == nil {
+			t.Errorf("%v: invalid curve not detected", c)
+		}
+		if y := c.Eval(0.5); !math.IsNaN(y) {
+			t.Errorf("%v: Eval returned %g", c, y)
+		}
+	}
+
+	para := []byte{'p', 'a', 'r', 'a', 0, 0, 0, 0, 0, 9, 0, 0, 0, 1, 0, 0}
+	if _, err := decodeCurve(para); err == nil {
+		t.Error("unknown function type not detected")
+	}
+}
@@ -0,0 +1,251 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"fmt"
+	"math"
+)
+
+// This file implements the CAM16 color appearance model from C. Li et al.,
+// "Comprehensive color solutions: CAM16, CAT16, and CAM16-UCS",
+// Color Research & Application 42(6), 2017.
+
+// Surround describes the luminance of the surround of a scene, relative to
+// the luminance of the scene white.
+type Surround int
+
+// The surround categories used by CIECAM02 and CAM16.
+const (
+	SurroundAverage Surround = iota
+	SurroundDim
+	SurroundDark
+)
+
+func (s Surround) String() string {
+	switch s {
+	case SurroundAverage:
+		return "Average"
+	case SurroundDim:
+		return "Dim"
+	case SurroundDark:
+		return "Dark"
+	default:
+		return fmt.Sprintf("Surround(%d)", int(s))
+	}
+}
+
+// CAM16Parameters describes the viewing conditions for the CAM16 model.
+type CAM16Parameters struct {
+	// White is the adopted white point, normalised to Y=1.
+	White XYZ
+
+	// AdaptingLuminance is the luminance of the adapting field L_A,
+	// in cd/m².
+	AdaptingLuminance float64
+
+	// BackgroundLuminance is the relative luminance Y_b of the background,
+	// in the range 0 to 100.  A typical value is 20.
+	BackgroundLuminance float64
+
+	Surround Surround
+}
+
+// CAM16 converts between CIE XYZ and the CAM16 appearance correlates for a
+// fixed set of viewing conditions.
+type CAM16 struct {
+	c, nc, fl, n, z, nbb, aw float64
+	d                        [3]float64
+}
+
+// CAM16Color holds the CAM16 appearance correlates of a color.
+type CAM16Color struct {
+	J float64 // lightness
+	C float64 // chroma
+	H float64 // hue angle, in degrees
+	M float64 // colorfulness
+	S float64 // saturation
+	Q float64 // brightness
+}
+
+var (
+	m16 = &matrix3{
+		0.401288, 0.650173, -0.051461,
+		-0.250268, 1.204414, 0.045854,
+		-0.002079, 0.048952, 0.953127,
+	}
+	m16Inv = m16.inv()
+)
+
+// NewCAM16 prepares the CAM16 model for the given viewing conditions.
+func NewCAM16(par *CAM16Parameters) *CAM16 {
+	var f, c, nc float64
+	switch par.Surround {
+	case SurroundDim:
+		f, c, nc = 0.9, 0.59, 0.9
+	case SurroundDark:
+		f, c, nc = 0.8, 0.525, 0.8
+	default:
+		f, c, nc = 1, 0.69, 1
+	}
+
+	la := par.AdaptingLuminance
+	k := 1 / (5*la + 1)
+	k4 := k * k * k * k
+	fl := 0.2*k4*(5*la) + 0.1*(1-k4)*(1-k4)*math.Cbrt(5*la)
+
+	n := par.BackgroundLuminance / 100
+	z := 1.48 + math.Sqrt(n)
+	nbb := 0.725 * math.Pow(n, -0.2)
+
+	d := f * (1 - math.Exp((-la-42)/92)/3.6)
+	d = math.Max(0, math.Min(1, d))
+
+	white := [3]float64{100 * par.White[0], 100 * par.White[1], 100 * par.White[2]}
+	rgbW := m16.apply(white)
+	m := &CAM16{
+		c:   c,
+		nc:  nc,
+		fl:  fl,
+		n:   n,
+		z:   z,
+		nbb: nbb,
+	}
+	for i := range m.d {
+		m.d[i] = d*white[1]/rgbW[i] + 1 - d
+	}
+
+	var rgbAW [3]float64
+	for i := range rgbAW {
+		rgbAW[i] = m.compress(m.d[i] * rgbW[i])
+	}
+	m.aw = (2*rgbAW[0] + rgbAW[1] + 0.05*rgbAW[2] - 0.305) * nbb
+
+	return m
+}
+
+// compress applies the post-adaptation non-linear response compression.
+func (m *CAM16) compress(x float64) float64 {
+	t := math.Pow(m.fl*math.Abs(x)/100, 0.42)
+	return math.Copysign(400*t/(t+27.13), x) + 0.1
+}
+
+// expand is the inverse of compress.
+func (m *CAM16) expand(x float64) float64 {
+	x -= 0.1
+	t := math.Abs(x)
+	return math.Copysign(100/m.fl*math.Pow(27.13*t/(400-t), 1/0.42), x)
+}
+
+// FromXYZ computes the appearance correlates of a color.  The XYZ values are
+// relative to the same scale as the white point in the viewing conditions,
+// i.e. Y=1 for a sample as light as the white.
+func (m *CAM16) FromXYZ(col XYZ) CAM16Color {
+	rgb := m16.apply([3]float64{100 * col[0], 100 * col[1], 100 * col[2]})
+	var rgbA [3]float64
+	for i := range rgbA {
+		rgbA[i] = m.compress(m.d[i] * rgb[i])
+	}
+
+	a := rgbA[0] - 12*rgbA[1]/11 + rgbA[2]/11
+	b := (rgbA[0] + rgbA[1] - 2*rgbA[2]) / 9
+	h := math.Atan2(b, a) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+
+	et := (math.Cos(h*math.Pi/180+2) + 3.8) / 4
+	A := (2*rgbA[0] + rgbA[1] + 0.05*rgbA[2] - 0.305) * m.nbb
+	J := 100 * math.Pow(A/m.aw, m.c*m.z)
+	Q := 4 / m.c * math.Sqrt(J/100) * (m.aw + 4) * math.Pow(m.fl, 0.25)
+
+	t := 50000.0 / 13 * m.nc * m.nbb * et * math.Hypot(a, b) /
+		(rgbA[0] + rgbA[1] + 21.0/20*rgbA[2])
+	C := math.Pow(t, 0.9) * math.Sqrt(J/100) * math.Pow(1.64-math.Pow(0.29, m.n), 0.73)
+	M := C * math.Pow(m.fl, 0.25)
+	s := 0.0
+	if Q > 0 {
+		s = 100 * math.Sqrt(M/Q)
+	}
+
+	return CAM16Color{J: J, C: C, H: h, M: M, S: s, Q: Q}
+}
+
+// ToXYZ converts lightness J, chroma C and hue angle h (in degrees) back to
+// XYZ.  This is the inverse of FromXYZ.
+func (m *CAM16) ToXYZ(J, C, h float64) XYZ {
+	var t float64
+	if J > 0 {
+		t = math.Pow(C/(math.Sqrt(J/100)*math.Pow(1.64-math.Pow(0.29, m.n), 0.73)), 1/0.9)
+	}
+	hr := h * math.Pi / 180
+	et := (math.Cos(hr+2) + 3.8) / 4
+	A := m.aw * math.Pow(J/100, 1/(m.c*m.z))
+
+	p2 := A/m.nbb + 0.305
+	const p3 = 21.0 / 20
+	var a, b float64
+	if t != 0 {
+		p1 := 50000.0 / 13 * m.nc * m.nbb * et / t
+		sin, cos := math.Sincos(hr)
+		if math.Abs(sin) >= math.Abs(cos) {
+			p4 := p1 / sin
+			b = p2 * (2 + p3) * (460.0 / 1403) /
+				(p4 + (2+p3)*(220.0/1403)*(cos/sin) - 27.0/1403 + p3*(6300.0/1403))
+			a = b * cos / sin
+		} else {
+			p5 := p1 / cos
+			a = p2 * (2 + p3) * (460.0 / 1403) /
+				(p5 + (2+p3)*(220.0/1403) - (27.0/1403-p3*(6300.0/1403))*(sin/cos))
+			b = a * sin / cos
+		}
+	}
+
+	rgbA := [3]float64{
+		(460*p2 + 451*a + 288*b) / 1403,
+		(460*p2 - 891*a - 261*b) / 1403,
+		(460*p2 - 220*a - 6300*b) / 1403,
+	}
+	var rgb [3]float64
+	for i := range rgb {
+		rgb[i] = m.expand(rgbA[i]) / m.d[i]
+	}
+	xyz := m16Inv.apply(rgb)
+	return XYZ{xyz[0] / 100, xyz[1] / 100, xyz[2] / 100}
+}
+
+// UCS returns the coordinates (J', a', b') of the color in the CAM16-UCS
+// uniform color space.
+func (c CAM16Color) UCS() [3]float64 {
+	Jp := 1.7 * c.J / (1 + 0.007*c.J)
+	Mp := math.Log1p(0.0228*c.M) / 0.0228
+	sin, cos := math.Sincos(c.H * math.Pi / 180)
+	return [3]float64{Jp, Mp * cos, Mp * sin}
+}
+
+// FromUCS converts CAM16-UCS coordinates (J', a', b') back to XYZ.
+func (m *CAM16) FromUCS(ucs [3]float64) XYZ {
+	J := ucs[0] / (1.7 - 0.007*ucs[0])
+	Mp := math.Hypot(ucs[1], ucs[2])
+	M := math.Expm1(0.0228*Mp) / 0.0228
+	h := math.Atan2(ucs[2], ucs[1]) * 180 / math.Pi
+	if h < 0 {
+		h += 360
+	}
+	C := M / math.Pow(m.fl, 0.25)
+	return m.ToXYZ(J, C, h)
+}
@@ -0,0 +1,99 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCAM16Reference(t *testing.T) {
+	// reference values from the colour-science Python package
+	m := NewCAM16(&CAM16Parameters{
+		White:               XYZ{0.9505, 1, 1.0888},
+		AdaptingLuminance:   318.31,
+		BackgroundLuminance: 20,
+		Surround:            SurroundAverage,
+	})
+	got := m.FromXYZ(XYZ{0.1901, 0.2000, 0.2178})
+	want := CAM16Color{
+		J: 41.73120791,
+		C: 0.10335574,
+		H: 217.06795977,
+		M: 0.10743677,
+		S: 2.34501507,
+		Q: 195.37170899,
+	}
+	check := func(name string, got, want float64) {
+		if math.Abs(got-want) > 1e-4*math.Max(1, math.Abs(want)) {
+			t.Errorf("%s: got %.8f, want %.8f", name, got, want)
+		}
+	}
+	check("J", got.J, want.J)
+	check("C", got.C, want.C)
+	check("h", got.H, want.H)
+	check("M", got.M, want.M)
+	check("s", got.S, want.S)
+	check("Q", got.Q, want.Q)
+}
+
+func TestCAM16RoundTrip(t *testing.T) {
+	for _, surround := range []Surround{SurroundAverage, SurroundDim, SurroundDark} {
+		m := NewCAM16(&CAM16Parameters{
+			White:               PCSIlluminant,
+			AdaptingLuminance:   64,
+			BackgroundLuminance: 20,
+			Surround:            surround,
+		})
+		for _, col := range []XYZ{
+			{0.2, 0.3, 0.4},
+			{0.5, 0.2, 0.05},
+			{0.05, 0.1, 0.6},
+			PCSIlluminant,
+		} {
+			cam := m.FromXYZ(col)
+			back := m.ToXYZ(cam.J, cam.C, cam.H)
+			back2 := m.FromUCS(cam.UCS())
+			for i := range col {
+				if math.Abs(back[i]-col[i]) > 1e-9 || math.Abs(back2[i]-col[i]) > 1e-9 {
+					t.Errorf("%s %v: got %v and %v", surround, col, back, back2)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestViewingConditions(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetViewingConditions(&ViewingConditions{
+		Illuminant:     XYZ{154.2, 160, 132},
+		Surround:       XYZ{30.8, 32, 26.4},
+		IlluminantType: 1,
+	})
+	v, err := p.ViewingConditions()
+	if err != nil {
+		t.Fatal(err)
+	}
+	par, err := v.CAM16Parameters()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if par.Surround != SurroundAverage || math.Abs(par.AdaptingLuminance-32) > 1e-3 {
+		t.Errorf("wrong parameters: %+v", par)
+	}
+}
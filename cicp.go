@@ -0,0 +1,70 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+// CICP contains the coding-independent code points for video signal type
+// identification, as defined in ITU-T H.273.
+type CICP struct {
+	ColorPrimaries          uint8
+	TransferCharacteristics uint8
+	MatrixCoefficients      uint8
+	VideoFullRange          bool
+}
+
+func decodeCICP(data []byte) (*CICP, error) {
+	err := checkType("cicp", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 || data[11] > 1 {
+		return nil, errInvalidTagData
+	}
+	res := &CICP{
+		ColorPrimaries:          data[8],
+		TransferCharacteristics: data[9],
+		MatrixCoefficients:      data[10],
+		VideoFullRange:          data[11] == 1,
+	}
+	return res, nil
+}
+
+func (c *CICP) encode() []byte {
+	buf := make([]byte, 12)
+	copy(buf, "cicp")
+	buf[8] = c.ColorPrimaries
+	buf[9] = c.TransferCharacteristics
+	buf[10] = c.MatrixCoefficients
+	if c.VideoFullRange {
+		buf[11] = 1
+	}
+	return buf
+}
+
+// CICP returns the contents of the coding-independent code points tag.
+func (p *Profile) CICP() (*CICP, error) {
+	tag, ok := p.TagData[CodingIndependentCodePoints]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeCICP(tag)
+}
+
+// SetCICP sets the coding-independent code points tag.
+func (p *Profile) SetCICP(c *CICP) {
+	p.SetTag(CodingIndependentCodePoints, c.encode())
+}
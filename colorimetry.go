@@ -0,0 +1,132 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"fmt"
+	"math"
+)
+
+// XYZ represents a color in the CIE XYZ color space, with Y=1 for
+// the reference white.
+type XYZ [3]float64
+
+// Lab represents a color in the CIELAB color space.
+type Lab [3]float64
+
+// Observer is a CIE standard colorimetric observer.
+type Observer int
+
+// The CIE standard observers.
+const (
+	Observer2  Observer = 2  // CIE 1931 2° standard observer
+	Observer10 Observer = 10 // CIE 1964 10° supplementary standard observer
+)
+
+func (o Observer) String() string {
+	switch o {
+	case Observer2:
+		return "2°"
+	case Observer10:
+		return "10°"
+	default:
+		return fmt.Sprintf("Observer(%d)", int(o))
+	}
+}
+
+// Illuminant is a CIE standard illuminant.
+type Illuminant int
+
+// Some CIE standard illuminants.
+const (
+	IlluminantD50 Illuminant = iota + 1
+	IlluminantD65
+)
+
+func (ill Illuminant) String() string {
+	switch ill {
+	case IlluminantD50:
+		return "D50"
+	case IlluminantD65:
+		return "D65"
+	default:
+		return fmt.Sprintf("Illuminant(%d)", int(ill))
+	}
+}
+
+// PCSIlluminant is the D50 white point used by the ICC profile connection
+// space, as stored in the profile header.  This differs slightly from the
+// CIE D50 white point returned by WhitePoint.
+var PCSIlluminant = XYZ{0.9642, 1, 0.8249}
+
+// WhitePoint returns the tristimulus values of the given illuminant for the
+// given observer, normalised to Y=1.  The values are taken from ASTM E308.
+// The function panics if the illuminant or observer is not known.
+func WhitePoint(ill Illuminant, obs Observer) XYZ {
+	switch {
+	case ill == IlluminantD50 && obs == Observer2:
+		return XYZ{0.96422, 1, 0.82521}
+	case ill == IlluminantD50 && obs == Observer10:
+		return XYZ{0.96720, 1, 0.81427}
+	case ill == IlluminantD65 && obs == Observer2:
+		return XYZ{0.95047, 1, 1.08883}
+	case ill == IlluminantD65 && obs == Observer10:
+		return XYZ{0.94811, 1, 1.07304}
+	default:
+		panic(fmt.Sprintf("icc: unknown white point %s/%s", ill, obs))
+	}
+}
+
+// ToLab converts the color to CIELAB, relative to the given white point.
+func (c XYZ) ToLab(white XYZ) Lab {
+	fx := labF(c[0] / white[0])
+	fy := labF(c[1] / white[1])
+	fz := labF(c[2] / white[2])
+	return Lab{
+		116*fy - 16,
+		500 * (fx - fy),
+		200 * (fy - fz),
+	}
+}
+
+// ToXYZ converts the color to CIE XYZ, relative to the given white point.
+func (c Lab) ToXYZ(white XYZ) XYZ {
+	fy := (c[0] + 16) / 116
+	fx := fy + c[1]/500
+	fz := fy - c[2]/200
+	return XYZ{
+		white[0] * labFInv(fx),
+		white[1] * labFInv(fy),
+		white[2] * labFInv(fz),
+	}
+}
+
+const labDelta = 6.0 / 29.0
+
+func labF(t float64) float64 {
+	if t > labDelta*labDelta*labDelta {
+		return math.Cbrt(t)
+	}
+	return t/(3*labDelta*labDelta) + 4.0/29.0
+}
+
+func labFInv(t float64) float64 {
+	if t > labDelta {
+		return t * t * t
+	}
+	return 3 * labDelta * labDelta * (t - 4.0/29.0)
+}
@@ -0,0 +1,116 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLabRoundTrip(t *testing.T) {
+	white := WhitePoint(IlluminantD65, Observer10)
+	for _, c := range []XYZ{
+		{0, 0, 0},
+		{0.001, 0.002, 0.003},
+		{0.2, 0.3, 0.4},
+		white,
+	} {
+		lab := c.ToLab(white)
+		got := lab.ToXYZ(white)
+		for i := range c {
+			if math.Abs(got[i]-c[i]) > 1e-12 {
+				t.Errorf("%v: got %v", c, got)
+				break
+			}
+		}
+	}
+
+	lab := white.ToLab(white)
+	if math.Abs(lab[0]-100) > 1e-12 || math.Abs(lab[1]) > 1e-12 || math.Abs(lab[2]) > 1e-12 {
+		t.Errorf("white maps to %v", lab)
+	}
+}
+
+func TestIlluminantA(t *testing.T) {
+	white := ReflectanceToXYZ(func(float64) float64 { return 1 }, SPDIlluminantA, CIE1931Approx)
+
+	// CIE 15:2004 gives x=0.44757, y=0.40745 for illuminant A.
+	sum := white[0] + white[1] + white[2]
+	x, y := white[0]/sum, white[1]/sum
+	if math.Abs(x-0.44757) > 0.002 || math.Abs(y-0.40745) > 0.002 {
+		t.Errorf("got chromaticity (%.5f, %.5f)", x, y)
+	}
+	if math.Abs(white[1]-1) > 1e-12 {
+		t.Errorf("got Y=%g, want 1", white[1])
+	}
+}
+
+func TestTabulatedWhitePoints(t *testing.T) {
+	perfectWhite := func(float64) float64 { return 1 }
+	for _, ill := range []Illuminant{IlluminantD50, IlluminantD65} {
+		for _, obs := range []Observer{Observer2, Observer10} {
+			got := ReflectanceToXYZ(perfectWhite, ill.SPD(), obs.CMF())
+			want := WhitePoint(ill, obs)
+			for i := range got {
+				if math.Abs(got[i]-want[i]) > 5e-4 {
+					t.Errorf("%s/%s: got %v, want %v", ill, obs, got, want)
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestEqualEnergyWhite(t *testing.T) {
+	white := SpectrumToXYZ(SPDIlluminantE, CIE1931)
+	sum := white[0] + white[1] + white[2]
+	x, y := white[0]/sum, white[1]/sum
+	if math.Abs(x-1.0/3) > 1e-4 || math.Abs(y-1.0/3) > 1e-4 {
+		t.Errorf("got chromaticity (%.5f, %.5f)", x, y)
+	}
+}
+
+func TestDaylight(t *testing.T) {
+	// values from the D65 table in CIE 15:2004
+	for _, c := range []struct{ lambda, want float64 }{
+		{380, 49.9755}, {460, 117.812}, {560, 100}, {700, 71.6091},
+	} {
+		if got := SPDIlluminantD65(c.lambda); math.Abs(got-c.want) > 1e-3 {
+			t.Errorf("D65(%g) = %g, want %g", c.lambda, got, c.want)
+		}
+	}
+	if got := SPDDaylight(6504)(560); math.Abs(got-100) > 1e-9 {
+		t.Errorf("daylight at 560nm = %g, want 100", got)
+	}
+}
+
+func TestSRGB(t *testing.T) {
+	white := SRGBToXYZ(1, 1, 1)
+	for i := range white {
+		if math.Abs(white[i]-PCSIlluminant[i]) > 0.0005 {
+			t.Errorf("sRGB white maps to %v, want %v", white, PCSIlluminant)
+			break
+		}
+	}
+
+	for _, rgb := range [][3]float64{{0, 0, 0}, {1, 0, 0}, {0.2, 0.5, 0.8}, {0.01, 0.02, 0.03}} {
+		r, g, b := XYZToSRGB(SRGBToXYZ(rgb[0], rgb[1], rgb[2]))
+		if math.Abs(r-rgb[0]) > 1e-9 || math.Abs(g-rgb[1]) > 1e-9 || math.Abs(b-rgb[2]) > 1e-9 {
+			t.Errorf("%v: got %g %g %g", rgb, r, g, b)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// corpusEnv names an environment variable which can be set to a directory
+// containing additional ICC profiles for TestCorpus.  The directory is
+// searched recursively.
+const corpusEnv = "ICC_CORPUS"
+
+// loadCorpus returns the paths of all profiles in testdata/ and in the
+// directory given by $ICC_CORPUS.
+func loadCorpus(t *testing.T) []string {
+	t.Helper()
+
+	dirs := []string{"testdata"}
+	if dir := os.Getenv(corpusEnv); dir != "" {
+		dirs = append(dirs, dir)
+	}
+
+	var res []string
+	for _, dir := range dirs {
+		err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			ext := strings.ToLower(filepath.Ext(path))
+			if !d.IsDir() && (ext == ".icc" || ext == ".icm") {
+				res = append(res, path)
+			}
+			return nil
+		})
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+	}
+	return res
+}
+
+// TestCorpus runs decode/encode smoke tests on the profiles in testdata/
+// and on an optional corpus of real-world profiles.
+func TestCorpus(t *testing.T) {
+	files := loadCorpus(t)
+	if len(files) == 0 {
+		t.Skip("no profiles found, set $" + corpusEnv + " to add a corpus")
+	}
+
+	for _, fname := range files {
+		t.Run(fname, func(t *testing.T) {
+			body, err := os.ReadFile(fname)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			p, err := DecodeWithOptions(bytes.Clone(body), &DecodeOptions{Lenient: true})
+			if err != nil {
+				t.Fatalf("decoding failed: %v", err)
+			}
+			for _, w := range p.Warnings {
+				t.Logf("warning: %v", w)
+			}
+
+			// The accessors must not panic, but may return errors for
+			// malformed tags in real-world profiles.  The profiles in
+			// testdata/ must be valid.
+			_ = p.Summary()
+			err = p.Validate()
+			if err != nil && strings.HasPrefix(fname, "testdata") {
+				t.Error(err)
+			}
+
+			data := p.Encode()
+			if len(data) != p.EncodedSize() {
+				t.Errorf("EncodedSize() = %d, len(Encode()) = %d", p.EncodedSize(), len(data))
+			}
+			q, err := Decode(data)
+			if err != nil {
+				t.Fatalf("re-decoding failed: %v", err)
+			}
+			if q.CheckSum == CheckSumInvalid {
+				t.Error("invalid checksum after re-encoding")
+			}
+			for tagType, tagData := range p.TagData {
+				if !bytes.Equal(tagData, q.TagData[tagType]) {
+					t.Errorf("tag %s changed", tagType)
+				}
+			}
+		})
+	}
+}
+
+// TestThirdPartyProfiles checks the decoded contents of the profiles in
+// testdata/ which were written by other software.
+func TestThirdPartyProfiles(t *testing.T) {
+	for _, test := range []struct {
+		fname    string
+		version  Version
+		checkSum CheckSum
+		desc     string
+		cprtType string
+	}{
+		{
+			fname:    "sRGB-elle-V2-srgbtrc.icc",
+			version:  Version2_1_0,
+			checkSum: CheckSumMissing,
+			desc:     "sRGB-elle-V2-srgbtrc.icc",
+			cprtType: "text",
+		},
+		{
+			fname:    "sRGB_IEC61966-2-1_black_scaled.icc",
+			version:  0x0200_0000,
+			checkSum: CheckSumValid,
+			desc:     "sRGB IEC61966-2-1 black scaled",
+			cprtType: "text",
+		},
+		{
+			fname:    "gimp-sRGB-v4.icc",
+			version:  Version4_3_0,
+			checkSum: CheckSumMissing,
+			desc:     "GIMP built-in sRGB",
+			cprtType: "mluc",
+		},
+	} {
+		t.Run(test.fname, func(t *testing.T) {
+			body, err := os.ReadFile(filepath.Join("testdata", test.fname))
+			if err != nil {
+				t.Fatal(err)
+			}
+			p, err := Decode(body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if p.Version != test.version {
+				t.Errorf("version %s, want %s", p.Version, test.version)
+			}
+			if p.CheckSum != test.checkSum {
+				t.Errorf("checksum %s, want %s", p.CheckSum, test.checkSum)
+			}
+			desc, err := p.Description()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := desc.Lookup("en", "US"); got != test.desc {
+				t.Errorf("description %q, want %q", got, test.desc)
+			}
+			if _, err := p.Copyright(); err != nil {
+				t.Error(err)
+			}
+			if got, _ := TagElementType(p.TagData[Copyright]); got != test.cprtType {
+				t.Errorf("copyright type %q, want %q", got, test.cprtType)
+			}
+		})
+	}
+}
@@ -0,0 +1,174 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"errors"
+	"math"
+)
+
+// Curve is a one-dimensional transfer function, described by one of the
+// parametric functions of the ICC parametricCurveType.
+//
+// The function types and their parameters are:
+//
+//	0: Y = X^g                                   Params: g
+//	1: Y = (aX+b)^g for X >= -b/a, else 0        Params: g, a, b
+//	2: Y = (aX+b)^g + c for X >= -b/a, else c    Params: g, a, b, c
+//	3: Y = (aX+b)^g for X >= d, else cX          Params: g, a, b, c, d
+//	4: Y = (aX+b)^g + e for X >= d, else cX + f  Params: g, a, b, c, d, e, f
+type Curve struct {
+	FunctionType int
+	Params       []float64
+}
+
+// GammaCurve returns the curve Y = X^gamma.
+func GammaCurve(gamma float64) *Curve {
+	return &Curve{FunctionType: 0, Params: []float64{gamma}}
+}
+
+// SRGBCurve returns the sRGB decoding function from IEC 61966-2-1.
+func SRGBCurve() *Curve {
+	return &Curve{
+		FunctionType: 3,
+		Params:       []float64{2.4, 1 / 1.055, 0.055 / 1.055, 1 / 12.92, 0.04045},
+	}
+}
+
+// numCurveParams gives the number of parameters for each function type.
+var numCurveParams = []int{1, 3, 4, 5, 7}
+
+// check verifies that the function type is known, that the number of
+// parameters matches the function type, and that all parameters are finite.
+// The exponent g must be positive.
+func (c *Curve) check() error {
+	if c.FunctionType < 0 || c.FunctionType >= len(numCurveParams) {
+		return errInvalidCurve
+	}
+	if len(c.Params) != numCurveParams[c.FunctionType] {
+		return errInvalidCurve
+	}
+	for _, x := range c.Params {
+		if !isFinite(x) {
+			return errInvalidCurve
+		}
+	}
+	if !(c.Params[0] > 0) {
+		return errInvalidCurve
+	}
+	return nil
+}
+
+// Eval evaluates the curve at x.  The input is clipped to the range
+// [0, 1].  If the curve is not valid, for example because the function
+// type is unknown or the number of parameters is wrong, NaN is returned.
+func (c *Curve) Eval(x float64) float64 {
+	if c.check() != nil {
+		return math.NaN()
+	}
+	x = min(max(x, 0), 1)
+	p := c.Params
+	pow := func(base float64) float64 {
+		return math.Pow(max(base, 0), p[0])
+	}
+	switch c.FunctionType {
+	case 0:
+		return pow(x)
+	case 1:
+		if p[1]*x+p[2] < 0 {
+			return 0
+		}
+		return pow(p[1]*x + p[2])
+	case 2:
+		if p[1]*x+p[2] < 0 {
+			return p[3]
+		}
+		return pow(p[1]*x+p[2]) + p[3]
+	case 3:
+		if x < p[4] {
+			return p[3] * x
+		}
+		return pow(p[1]*x + p[2])
+	case 4:
+		if x < p[4] {
+			return p[3]*x + p[6]
+		}
+		return pow(p[1]*x+p[2]) + p[5]
+	default:
+		return math.NaN()
+	}
+}
+
+// decodeCurve decodes a parametricCurveType element, or a curveType
+// element which describes a pure gamma function.
+func decodeCurve(data []byte) (*Curve, error) {
+	if len(data) < 12 {
+		return nil, errInvalidTagData
+	}
+
+	switch string(data[:4]) {
+	case "curv":
+		switch getUint32(data, 8) {
+		case 0:
+			return GammaCurve(1), nil
+		case 1:
+			if len(data) < 14 {
+				return nil, errInvalidTagData
+			}
+			return GammaCurve(DecodeU8Fixed8(getUint16(data, 12))), nil
+		default:
+			return nil, errUnexpectedType
+		}
+
+	case "para":
+		c := &Curve{FunctionType: int(getUint16(data, 8))}
+		if c.FunctionType >= len(numCurveParams) {
+			return nil, errInvalidTagData
+		}
+		n := numCurveParams[c.FunctionType]
+		if len(data) < 12+4*n {
+			return nil, errInvalidTagData
+		}
+		c.Params = make([]float64, n)
+		for i := range c.Params {
+			c.Params[i] = getS15Fixed16(data, 12+4*i)
+		}
+		return c, nil
+
+	default:
+		return nil, errUnexpectedType
+	}
+}
+
+// encode encodes the curve as a parametricCurveType element.
+func (c *Curve) encode() []byte {
+	buf := make([]byte, 12+4*len(c.Params))
+	copy(buf, "para")
+	buf[8] = byte(c.FunctionType >> 8)
+	buf[9] = byte(c.FunctionType)
+	for i, x := range c.Params {
+		putS15Fixed16(buf, 12+4*i, x)
+	}
+	return buf
+}
+
+// isFinite reports whether x is neither NaN nor an infinity.
+func isFinite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
+}
+
+var errInvalidCurve = errors.New("invalid curve parameters")
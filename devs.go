@@ -0,0 +1,195 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "errors"
+
+// DeviceSettings is the contents of the device settings tag.  This tag was
+// defined in version 2 of the ICC specification and removed in version 4.
+type DeviceSettings []*DevicePlatformSettings
+
+// DevicePlatformSettings lists the setting combinations for one platform.
+type DevicePlatformSettings struct {
+	// Platform is the platform signature, for example 0x6D736674 ("msft").
+	Platform uint32
+
+	// Combinations lists the setting combinations for which the profile
+	// is valid.
+	Combinations [][]*DeviceSetting
+}
+
+// DeviceSetting is a single setting, with one or more allowed values.
+type DeviceSetting struct {
+	ID uint32
+
+	// Values contains the raw setting values.  All values must have the
+	// same, non-zero length.
+	Values [][]byte
+}
+
+func decodeDeviceSettings(data []byte) (DeviceSettings, error) {
+	err := checkType("devs", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 12 {
+		return nil, errInvalidTagData
+	}
+	numPlatforms := getUint32(data, 8)
+	if uint64(numPlatforms) > uint64(len(data)-12)/12 {
+		return nil, errInvalidTagData
+	}
+
+	res := make(DeviceSettings, numPlatforms)
+	pos := 12
+	for i := range res {
+		if len(data)-pos < 12 {
+			return nil, errInvalidTagData
+		}
+		platformSize := getUint32(data, pos+4)
+		numCombinations := getUint32(data, pos+8)
+		if platformSize < 12 || uint64(platformSize) > uint64(len(data)-pos) ||
+			uint64(numCombinations) > uint64(platformSize-12)/8 {
+			return nil, errInvalidTagData
+		}
+		end := pos + int(platformSize)
+		platform := &DevicePlatformSettings{
+			Platform:     getUint32(data, pos),
+			Combinations: make([][]*DeviceSetting, numCombinations),
+		}
+
+		cPos := pos + 12
+		for j := range platform.Combinations {
+			if end-cPos < 8 {
+				return nil, errInvalidTagData
+			}
+			combSize := getUint32(data, cPos)
+			numSettings := getUint32(data, cPos+4)
+			if combSize < 8 || uint64(combSize) > uint64(end-cPos) ||
+				uint64(numSettings) > uint64(combSize-8)/12 {
+				return nil, errInvalidTagData
+			}
+			combEnd := cPos + int(combSize)
+			settings := make([]*DeviceSetting, numSettings)
+
+			sPos := cPos + 8
+			for k := range settings {
+				if combEnd-sPos < 12 {
+					return nil, errInvalidTagData
+				}
+				valueSize := uint64(getUint32(data, sPos+4))
+				numValues := uint64(getUint32(data, sPos+8))
+				if valueSize == 0 && numValues > 0 ||
+					valueSize*numValues > uint64(combEnd-sPos-12) {
+					return nil, errInvalidTagData
+				}
+				setting := &DeviceSetting{
+					ID:     getUint32(data, sPos),
+					Values: make([][]byte, numValues),
+				}
+				vPos := sPos + 12
+				for l := range setting.Values {
+					setting.Values[l] = data[vPos : vPos+int(valueSize)]
+					vPos += int(valueSize)
+				}
+				settings[k] = setting
+				sPos = vPos
+			}
+
+			platform.Combinations[j] = settings
+			cPos = combEnd
+		}
+
+		res[i] = platform
+		pos = end
+	}
+	return res, nil
+}
+
+func (d DeviceSettings) encode() ([]byte, error) {
+	buf := make([]byte, 12)
+	copy(buf, "devs")
+	putUint32(buf, 8, uint32(len(d)))
+	for _, platform := range d {
+		start := len(buf)
+		buf = append(buf, make([]byte, 12)...)
+		putUint32(buf, start, platform.Platform)
+		putUint32(buf, start+8, uint32(len(platform.Combinations)))
+		for _, settings := range platform.Combinations {
+			cStart := len(buf)
+			buf = append(buf, make([]byte, 8)...)
+			putUint32(buf, cStart+4, uint32(len(settings)))
+			for _, setting := range settings {
+				valueSize := 0
+				if len(setting.Values) > 0 {
+					valueSize = len(setting.Values[0])
+				}
+				for _, v := range setting.Values {
+					if len(v) != valueSize || valueSize == 0 {
+						return nil, errInvalidSettingValues
+					}
+				}
+
+				sStart := len(buf)
+				buf = append(buf, make([]byte, 12)...)
+				putUint32(buf, sStart, setting.ID)
+				putUint32(buf, sStart+4, uint32(valueSize))
+				putUint32(buf, sStart+8, uint32(len(setting.Values)))
+				for _, v := range setting.Values {
+					buf = append(buf, v...)
+				}
+			}
+			putUint32(buf, cStart, uint32(len(buf)-cStart))
+		}
+		putUint32(buf, start+4, uint32(len(buf)-start))
+	}
+	return buf, nil
+}
+
+// DeviceSettings returns the contents of the device settings tag.
+func (p *Profile) DeviceSettings() (DeviceSettings, error) {
+	tag, ok := p.TagData[DeviceSettingsTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeDeviceSettings(tag)
+}
+
+// SetDeviceSettings sets the device settings tag.
+// The tag is only allowed in version 2 profiles.
+// An error is returned if the values of a setting differ in length.
+func (p *Profile) SetDeviceSettings(d DeviceSettings) error {
+	data, err := d.encode()
+	if err != nil {
+		return err
+	}
+	p.SetTag(DeviceSettingsTag, data)
+	return nil
+}
+
+// deprecatedTags lists tags which are defined in version 2 of the ICC
+// specification, but were removed in version 4.
+var deprecatedTags = []TagType{
+	CRDInfoTag,
+	DeviceSettingsTag,
+	NamedColorTag,
+	ScreeningDescTag,
+	UCRBGTag,
+}
+
+var errInvalidSettingValues = errors.New("device setting values differ in length")
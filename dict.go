@@ -0,0 +1,256 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"slices"
+)
+
+// Dict is the contents of a dictType tag element, as used by the metadata
+// tag.  The order of the entries is preserved.
+type Dict []*DictEntry
+
+// DictEntry is a single name-value pair in a Dict.
+type DictEntry struct {
+	Name  string
+	Value string
+
+	// DisplayName and DisplayValue are optional localized versions of the
+	// name and value, intended to be shown to the user.
+	DisplayName  MultiLocalizedUnicode
+	DisplayValue MultiLocalizedUnicode
+}
+
+// Lookup returns the value of the first entry with the given name.
+func (d Dict) Lookup(name string) (string, bool) {
+	for _, e := range d {
+		if e.Name == name {
+			return e.Value, true
+		}
+	}
+	return "", false
+}
+
+// Set returns a copy of d where the value of the first entry with the
+// given name is replaced.  If there is no such entry, a new entry is
+// appended.  The display value of a replaced entry is removed.  The
+// original Dict and its entries are not modified.
+func (d Dict) Set(name, value string) Dict {
+	res := slices.Clone(d)
+	for i, e := range res {
+		if e.Name == name {
+			e2 := *e
+			e2.Value = value
+			e2.DisplayValue = nil
+			res[i] = &e2
+			return res
+		}
+	}
+	return append(res, &DictEntry{Name: name, Value: value})
+}
+
+func decodeDict(data []byte) (Dict, error) {
+	err := checkType("dict", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 16 {
+		return nil, errInvalidTagData
+	}
+	numRecords := getUint32(data, 8)
+	recordLen := getUint32(data, 12)
+	if recordLen != 16 && recordLen != 24 && recordLen != 32 ||
+		uint64(numRecords) > uint64(len(data)-16)/uint64(recordLen) {
+		return nil, errInvalidTagData
+	}
+
+	// getPart returns the data referenced by the offset and size fields
+	// at pos, or nil if the offset is zero.
+	getPart := func(pos int) ([]byte, error) {
+		offset := uint64(getUint32(data, pos))
+		size := uint64(getUint32(data, pos+4))
+		if offset == 0 {
+			return nil, nil
+		}
+		if offset < 16 || offset+size > uint64(len(data)) {
+			return nil, errInvalidTagData
+		}
+		return data[offset : offset+size], nil
+	}
+
+	res := make(Dict, numRecords)
+	for i := range res {
+		pos := 16 + i*int(recordLen)
+		e := &DictEntry{}
+
+		var parts [4][]byte
+		for j := 0; j < int(recordLen)/8; j++ {
+			parts[j], err = getPart(pos + 8*j)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if parts[0] == nil {
+			return nil, errInvalidTagData
+		}
+		e.Name, err = decodeUTF16BE(parts[0], false)
+		if err != nil {
+			return nil, err
+		}
+		e.Value, err = decodeUTF16BE(parts[1], false)
+		if err != nil {
+			return nil, err
+		}
+		if parts[2] != nil {
+			e.DisplayName, err = decodeMLUC(parts[2], false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if parts[3] != nil {
+			e.DisplayValue, err = decodeMLUC(parts[3], false)
+			if err != nil {
+				return nil, err
+			}
+		}
+		res[i] = e
+	}
+	return res, nil
+}
+
+// encode encodes d as a dictType element.  An error is returned if a
+// display name or display value contains invalid language or country codes.
+func (d Dict) encode() ([]byte, error) {
+	recordLen := 16
+	for _, e := range d {
+		if e.DisplayValue != nil {
+			recordLen = 32
+			break
+		} else if e.DisplayName != nil {
+			recordLen = 24
+		}
+	}
+
+	buf := make([]byte, 16+len(d)*recordLen)
+	copy(buf, "dict")
+	putUint32(buf, 8, uint32(len(d)))
+	putUint32(buf, 12, uint32(recordLen))
+
+	// putPart appends part to the buffer and stores the offset and size
+	// at position pos.  Elements are aligned to four-byte boundaries.
+	putPart := func(pos int, part []byte) {
+		for len(buf)%4 != 0 {
+			buf = append(buf, 0)
+		}
+		putUint32(buf, pos, uint32(len(buf)))
+		putUint32(buf, pos+4, uint32(len(part)))
+		buf = append(buf, part...)
+	}
+	for i, e := range d {
+		pos := 16 + i*recordLen
+		putPart(pos, encodeUTF16BE(e.Name))
+		putPart(pos+8, encodeUTF16BE(e.Value))
+		if e.DisplayName != nil {
+			part, err := e.DisplayName.Encode()
+			if err != nil {
+				return nil, err
+			}
+			putPart(pos+16, part)
+		}
+		if e.DisplayValue != nil {
+			part, err := e.DisplayValue.Encode()
+			if err != nil {
+				return nil, err
+			}
+			putPart(pos+24, part)
+		}
+	}
+	return buf, nil
+}
+
+// Metadata returns the contents of the metadata tag.
+func (p *Profile) Metadata() (Dict, error) {
+	tag, ok := p.TagData[MetadataTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeDict(tag)
+}
+
+// SetMetadata sets the metadata tag.  The tag was introduced in version
+// 4.3 of the ICC specification.  An error is returned if a display name or
+// display value contains invalid language or country codes.
+func (p *Profile) SetMetadata(d Dict) error {
+	data, err := d.encode()
+	if err != nil {
+		return err
+	}
+	p.SetTag(MetadataTag, data)
+	return nil
+}
+
+// SetMetadataValue sets a single entry in the metadata tag, keeping all
+// other entries.  A malformed metadata tag is replaced.  An error is
+// returned if the existing entries cannot be encoded, see SetMetadata.
+func (p *Profile) SetMetadataValue(name, value string) error {
+	d, _ := p.Metadata()
+	return p.SetMetadata(d.Set(name, value))
+}
+
+// EmbedFingerprint stores the SHA-256 hash of data in the metadata tag,
+// under the given name.  This can be used to trace a generated profile
+// back to its inputs, for example to the measurement data used to build
+// the profile.  Errors are as for SetMetadataValue.
+func (p *Profile) EmbedFingerprint(name string, data []byte) error {
+	return p.SetMetadataValue(name, fingerprint(data))
+}
+
+// VerifyFingerprint checks whether the metadata tag contains the SHA-256
+// hash of data under the given name, as stored by EmbedFingerprint.
+// An error is returned if the metadata tag or the entry is missing or
+// malformed.
+func (p *Profile) VerifyFingerprint(name string, data []byte) (bool, error) {
+	d, err := p.Metadata()
+	if err != nil {
+		return false, err
+	}
+	val, ok := d.Lookup(name)
+	if !ok {
+		return false, errMissingFingerprint
+	}
+	if len(val) != len(fingerprintPrefix)+2*sha256.Size ||
+		val[:len(fingerprintPrefix)] != fingerprintPrefix {
+		return false, errInvalidFingerprint
+	}
+	return val == fingerprint(data), nil
+}
+
+func fingerprint(data []byte) string {
+	sum := sha256.Sum256(data)
+	return fingerprintPrefix + hex.EncodeToString(sum[:])
+}
+
+const fingerprintPrefix = "sha256:"
+
+var (
+	errMissingFingerprint = errors.New("missing fingerprint")
+	errInvalidFingerprint = errors.New("invalid fingerprint")
+)
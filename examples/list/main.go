@@ -94,18 +94,23 @@ func show(fname string) error {
 	for _, t := range tags {
 		data := p.TagData[t]
 		switch t {
-		case icc.Copyright:
+		case icc.Copyright, icc.ProfileDescription:
 			fmt.Printf("  %s: (%d bytes)\n", t, len(data))
-			cprt, err := p.Copyright()
+			var text icc.MultiLocalizedUnicode
+			if t == icc.Copyright {
+				text, err = p.Copyright()
+			} else {
+				text, err = p.Description()
+			}
 			if err != nil {
 				return err
 			}
-			for _, lu := range cprt {
+			for _, lu := range text {
 				fmt.Printf("    [%s_%s] %s\n", lu.Language, lu.Country, lu.Value)
 			}
 		default:
-			sig := uint32(data[0])<<24 | uint32(data[1])<<16 | uint32(data[2])<<8 | uint32(data[3])
-			fmt.Printf("  %s: %s (%d bytes)\n", t, tag(sig), len(data))
+			elemType, _ := icc.TagElementType(data)
+			fmt.Printf("  %s: %q (%d bytes)\n", t, elemType, len(data))
 		}
 	}
 
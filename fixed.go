@@ -0,0 +1,90 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "math"
+
+// This file implements the fixed-point number formats used in ICC profiles.
+// All encoders round to the nearest representable value, with ties rounded
+// away from zero.  Values outside the representable range are clamped,
+// and NaN is encoded as zero.
+
+// DecodeS15Fixed16 converts an s15Fixed16Number to a float64.
+func DecodeS15Fixed16(x int32) float64 {
+	return float64(x) / 65536
+}
+
+// EncodeS15Fixed16 converts a float64 to an s15Fixed16Number.
+// The representable range is -32768 to 32767 + 65535/65536.
+func EncodeS15Fixed16(x float64) int32 {
+	return int32(encodeFixed(x, 65536, math.MinInt32, math.MaxInt32))
+}
+
+// DecodeU16Fixed16 converts a u16Fixed16Number to a float64.
+func DecodeU16Fixed16(x uint32) float64 {
+	return float64(x) / 65536
+}
+
+// EncodeU16Fixed16 converts a float64 to a u16Fixed16Number.
+// The representable range is 0 to 65535 + 65535/65536.
+func EncodeU16Fixed16(x float64) uint32 {
+	return uint32(encodeFixed(x, 65536, 0, math.MaxUint32))
+}
+
+// DecodeU8Fixed8 converts a u8Fixed8Number to a float64.
+func DecodeU8Fixed8(x uint16) float64 {
+	return float64(x) / 256
+}
+
+// EncodeU8Fixed8 converts a float64 to a u8Fixed8Number.
+// The representable range is 0 to 255 + 255/256.
+func EncodeU8Fixed8(x float64) uint16 {
+	return uint16(encodeFixed(x, 256, 0, math.MaxUint16))
+}
+
+// DecodeU1Fixed15 converts a u1Fixed15Number to a float64.
+func DecodeU1Fixed15(x uint16) float64 {
+	return float64(x) / 32768
+}
+
+// EncodeU1Fixed15 converts a float64 to a u1Fixed15Number.
+// The representable range is 0 to 1 + 32767/32768.
+func EncodeU1Fixed15(x float64) uint16 {
+	return uint16(encodeFixed(x, 32768, 0, math.MaxUint16))
+}
+
+// encodeFixed computes round(x*scale), clamped to the range [lo, hi].
+func encodeFixed(x, scale float64, lo, hi int64) int64 {
+	if math.IsNaN(x) {
+		return 0
+	}
+	y := math.Round(x * scale)
+	if y <= float64(lo) {
+		return lo
+	} else if y >= float64(hi) {
+		return hi
+	}
+	return int64(y)
+}
+
+func getS15Fixed16(data []byte, offset int) float64 {
+	return DecodeS15Fixed16(int32(getUint32(data, offset)))
+}
+
+func putS15Fixed16(data []byte, offset int, value float64) {
+	putUint32(data, offset, uint32(EncodeS15Fixed16(value)))
+}
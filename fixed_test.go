@@ -0,0 +1,76 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"math"
+	"testing"
+)
+
+func TestS15Fixed16(t *testing.T) {
+	cases := []struct {
+		in   float64
+		want int32
+	}{
+		{0, 0},
+		{1, 0x00010000},
+		{-1, -0x00010000},
+		{0.9642, 0x0000F6D6}, // PCS illuminant X
+		{0.8249, 0x0000D32D}, // PCS illuminant Z
+		{0.4 / 65536, 0},
+		{0.6 / 65536, 1},
+		{-0.6 / 65536, -1},
+		{40000, math.MaxInt32},
+		{-40000, math.MinInt32},
+		{math.NaN(), 0},
+	}
+	for _, c := range cases {
+		got := EncodeS15Fixed16(c.in)
+		if got != c.want {
+			t.Errorf("EncodeS15Fixed16(%g) = 0x%08X, want 0x%08X", c.in, got, c.want)
+		}
+	}
+
+	for _, x := range []int32{0, 1, -1, 0x12345678, math.MinInt32, math.MaxInt32} {
+		if got := EncodeS15Fixed16(DecodeS15Fixed16(x)); got != x {
+			t.Errorf("round trip of 0x%08X gave 0x%08X", x, got)
+		}
+	}
+}
+
+func TestUnsignedFixed(t *testing.T) {
+	if got := EncodeU1Fixed15(1); got != 0x8000 {
+		t.Errorf("EncodeU1Fixed15(1) = 0x%04X", got)
+	}
+	if got := EncodeU1Fixed15(2); got != 0xFFFF {
+		t.Errorf("EncodeU1Fixed15(2) = 0x%04X", got)
+	}
+	if got := EncodeU8Fixed8(2.2); got != 0x0233 {
+		t.Errorf("EncodeU8Fixed8(2.2) = 0x%04X", got)
+	}
+	if got := EncodeU16Fixed16(-1); got != 0 {
+		t.Errorf("EncodeU16Fixed16(-1) = 0x%08X", got)
+	}
+	for _, x := range []uint16{0, 1, 0x8000, 0xFFFF} {
+		if got := EncodeU1Fixed15(DecodeU1Fixed15(x)); got != x {
+			t.Errorf("u1Fixed15 round trip of 0x%04X gave 0x%04X", x, got)
+		}
+		if got := EncodeU8Fixed8(DecodeU8Fixed8(x)); got != x {
+			t.Errorf("u8Fixed8 round trip of 0x%04X gave 0x%04X", x, got)
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"bufio"
+	"bytes"
+	"compress/zlib"
+	"io"
+)
+
+// WriteFlate writes the binary form of the profile to w, compressed using
+// the zlib format.  The output can be used directly as the contents of a PDF
+// stream with the /FlateDecode filter.
+func (p *Profile) WriteFlate(w io.Writer) error {
+	zw, err := zlib.NewWriterLevel(w, zlib.BestCompression)
+	if err != nil {
+		return err
+	}
+	_, err = zw.Write(p.Encode())
+	if err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// EncodeFlate returns the binary form of the profile, compressed using the
+// zlib format.  See WriteFlate for details.
+func (p *Profile) EncodeFlate() []byte {
+	buf := &bytes.Buffer{}
+	_ = p.WriteFlate(buf) // writing to a bytes.Buffer cannot fail
+	return buf.Bytes()
+}
+
+// Read reads an ICC profile from r.  The data can either be an
+// uncompressed profile, or a profile compressed using the zlib format
+// as produced by WriteFlate.  Compressed data is detected automatically.
+func Read(r io.Reader) (*Profile, error) {
+	br := bufio.NewReader(r)
+	head, _ := br.Peek(40)
+
+	var in io.Reader = br
+	if isCompressed(head) {
+		zr, err := zlib.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+		in = zr
+	}
+
+	data, err := io.ReadAll(in)
+	if err != nil {
+		return nil, err
+	}
+	return Decode(data)
+}
+
+// isCompressed checks whether data starts with a zlib header using the
+// deflate method.  Uncompressed profiles start with the profile size, and
+// for large profiles the first two bytes can pass the zlib header check.
+// Therefore, data which has the profile signature "acsp" at offset 36 is
+// never treated as compressed.
+func isCompressed(data []byte) bool {
+	if len(data) >= 40 && string(data[36:40]) == "acsp" {
+		return false
+	}
+	if len(data) < 2 {
+		return false
+	}
+	cmf, flg := data[0], data[1]
+	return cmf&0x0F == 8 && cmf>>4 <= 7 && (uint(cmf)<<8|uint(flg))%31 == 0
+}
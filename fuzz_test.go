@@ -0,0 +1,287 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func FuzzMLUC(f *testing.F) {
+	for _, m := range []MultiLocalizedUnicode{
+		{{Language: "en", Country: "US", Value: "Test"}},
+		{
+			{Language: "en", Country: "US", Value: "colour"},
+			{Language: "de", Country: "DE", Value: "Farbe \U0001F3A8"},
+			{Language: "en", Country: "GB", Value: "colour"},
+		},
+		{{Language: "la", Value: "color"}},
+		{},
+	} {
+		seed, err := m.Encode()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(seed)
+	}
+	f.Add([]byte("mluc\x00\x00\x00\x00\x00\x00\x00\x01\x00\x00\x00\x0c" +
+		"enUS\x00\x00\x00\x06\x00\x00\x00\x1c" +
+		"\xfe\xff\xfe\xff\x00x")) // literal U+FEFF after the byte order mark
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, lenient := range []bool{false, true} {
+			m1, err := decodeMLUC(data, lenient)
+			if err != nil {
+				continue
+			}
+			data2, err := m1.Encode()
+			if err != nil {
+				continue // the decoder does not check language codes
+			}
+			m2, err := decodeMLUC(data2, false)
+			if err != nil {
+				t.Fatalf("re-decoding failed: %v", err)
+			}
+			if d := cmp.Diff(m1, m2); d != "" {
+				t.Fatalf("round trip failed (-want +got):\n%s", d)
+			}
+		}
+	})
+}
+
+func FuzzText(f *testing.F) {
+	f.Add(encodeText("Public Domain"))
+	f.Add(encodeTextDescription("sRGB"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		_, _ = decodeText(data)
+		_, _ = decodeTextDescription(data)
+	})
+}
+
+func FuzzSignature(f *testing.F) {
+	f.Add(encodeSignature(uint32(PerceptualReferenceMediumGamut)))
+	f.Add((&CICP{ColorPrimaries: 9, TransferCharacteristics: 16, VideoFullRange: true}).encode())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if sig, err := decodeSignature(data); err == nil {
+			sig2, err := decodeSignature(encodeSignature(sig))
+			if err != nil || sig2 != sig {
+				t.Fatalf("signature round trip failed: %08X %08X %v", sig, sig2, err)
+			}
+		}
+		if c, err := decodeCICP(data); err == nil {
+			c2, err := decodeCICP(c.encode())
+			if err != nil || *c2 != *c {
+				t.Fatalf("cicp round trip failed: %v %v %v", c, c2, err)
+			}
+		}
+	})
+}
+
+func FuzzWCSProfiles(f *testing.F) {
+	f.Add((&WCSProfiles{ColorDeviceModel: []byte("<cdm/>")}).encode())
+	f.Fuzz(func(t *testing.T, data []byte) {
+		w, err := decodeWCSProfiles(data)
+		if err != nil {
+			return
+		}
+		_ = w.ColorDeviceModelXML()
+		w2, err := decodeWCSProfiles(w.encode())
+		if err != nil {
+			t.Fatalf("re-decoding failed: %v", err)
+		}
+		if d := cmp.Diff(w, w2, cmp.Comparer(func(a, b []byte) bool {
+			return string(a) == string(b)
+		})); d != "" {
+			t.Fatalf("round trip failed (-want +got):\n%s", d)
+		}
+	})
+}
+
+func FuzzApple(f *testing.F) {
+	f.Add((&MakeAndModel{Manufacturer: 0x4150504C, Model: 1}).encode())
+	for _, n := range []*NativeDisplayInfo{
+		{Gamma: [3]float64{2.2, 2.2, 2.2}},
+		{GammaTable: [][]uint16{{0, 65535}}, GammaEntrySize: 2},
+	} {
+		data, err := n.encode()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	for _, v := range []*VideoCardGamma{
+		{Table: [][]uint16{{0, 128, 255}}, EntrySize: 1},
+		{Formula: [3]VideoCardGammaFormula{{Gamma: 1, Max: 1}}},
+	} {
+		data, err := v.encode()
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(data)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if m, err := decodeMakeAndModel(data); err == nil {
+			m2, err := decodeMakeAndModel(m.encode())
+			if err != nil || *m2 != *m {
+				t.Fatalf("mmod round trip failed: %v %v %v", m, m2, err)
+			}
+		}
+		if n, err := decodeNativeDisplayInfo(data); err == nil {
+			data2, err := n.encode()
+			if err != nil {
+				t.Fatalf("ndin re-encoding failed: %v", err)
+			}
+			n2, err := decodeNativeDisplayInfo(data2)
+			if err != nil {
+				t.Fatalf("ndin re-decoding failed: %v", err)
+			}
+			if d := cmp.Diff(n, n2); d != "" {
+				t.Fatalf("ndin round trip failed (-want +got):\n%s", d)
+			}
+		}
+		if v, err := decodeVideoCardGamma(data); err == nil {
+			data2, err := v.encode()
+			if err != nil {
+				t.Fatalf("vcgt re-encoding failed: %v", err)
+			}
+			v2, err := decodeVideoCardGamma(data2)
+			if err != nil {
+				t.Fatalf("vcgt re-decoding failed: %v", err)
+			}
+			if d := cmp.Diff(v, v2); d != "" {
+				t.Fatalf("vcgt round trip failed (-want +got):\n%s", d)
+			}
+		}
+	})
+}
+
+func FuzzDeviceSettings(f *testing.F) {
+	seed, err := DeviceSettings{
+		{
+			Platform:     0x6D736674,
+			Combinations: [][]*DeviceSetting{{{ID: 1, Values: [][]byte{{1, 2}}}}},
+		},
+	}.encode()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(seed)
+	f.Add(devsZeroSizeValues)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := decodeDeviceSettings(data)
+		if err != nil {
+			return
+		}
+		data2, err := d.encode()
+		if err != nil {
+			t.Fatalf("re-encoding failed: %v", err)
+		}
+		d2, err := decodeDeviceSettings(data2)
+		if err != nil {
+			t.Fatalf("re-decoding failed: %v", err)
+		}
+		if diff := cmp.Diff(d, d2); diff != "" {
+			t.Fatalf("round trip failed (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func FuzzDict(f *testing.F) {
+	seed, err := Dict{
+		{Name: "a", Value: "b"},
+		{Name: "c", DisplayName: MultiLocalizedUnicode{{Language: "en", Country: "US", Value: "C"}}},
+	}.encode()
+	if err != nil {
+		f.Fatal(err)
+	}
+	f.Add(seed)
+	f.Fuzz(func(t *testing.T, data []byte) {
+		d, err := decodeDict(data)
+		if err != nil {
+			return
+		}
+		data2, err := d.encode()
+		if err != nil {
+			return // the decoder does not check language codes
+		}
+		d2, err := decodeDict(data2)
+		if err != nil {
+			t.Fatalf("re-decoding failed: %v", err)
+		}
+		if diff := cmp.Diff(d, d2); diff != "" {
+			t.Fatalf("round trip failed (-want +got):\n%s", diff)
+		}
+	})
+}
+
+func FuzzCurve(f *testing.F) {
+	f.Add(GammaCurve(2.2).encode())
+	f.Add(SRGBCurve().encode())
+	f.Add((&Curve{FunctionType: 4, Params: []float64{2.2, 0.9, 0.1, 0.05, 0.1, 0.01, 0.002}}).encode())
+	f.Add([]byte("curv\x00\x00\x00\x00\x00\x00\x00\x00"))         // identity
+	f.Add([]byte("curv\x00\x00\x00\x00\x00\x00\x00\x01\x01\xcd")) // gamma 1.8
+	f.Add([]byte("curv\x00\x00\x00\x00\x00\x00\x00\x01"))         // truncated
+	f.Add([]byte("curv\x00\x00\x00\x00\xff\xff\xff\xff\x00\x00")) // oversized count
+	f.Add([]byte("para\x00\x00\x00\x00\x00\x04\x00\x00\x00\x02")) // truncated
+	f.Add([]byte("para\x00\x00\x00\x00\xff\xff\x00\x00\x00\x01\x00\x00"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		c, err := decodeCurve(data)
+		if err != nil {
+			return
+		}
+		for _, x := range []float64{-1, 0, 0.5, 1, 2} {
+			_ = c.Eval(x)
+		}
+		c2, err := decodeCurve(c.encode())
+		if err != nil {
+			t.Fatalf("re-decoding failed: %v", err)
+		}
+		if d := cmp.Diff(c, c2); d != "" {
+			t.Fatalf("round trip failed (-want +got):\n%s", d)
+		}
+	})
+}
+
+func FuzzViewingConditions(f *testing.F) {
+	view := (&ViewingConditions{
+		Illuminant:     XYZ{19.6445, 20.3718, 16.8089},
+		Surround:       XYZ{3.9289, 4.0744, 3.3618},
+		IlluminantType: 1,
+	}).encode()
+	f.Add(view)
+	f.Add(view[:35]) // truncated
+	f.Add(encodeXYZ(PCSIlluminant))
+	f.Add(encodeXYZ(PCSIlluminant)[:19]) // truncated
+	f.Add([]byte("XYZ \x00\x00\x00\x00\x7f\xff\xff\xff\x80\x00\x00\x00\xff\xff\xff\xff"))
+	f.Fuzz(func(t *testing.T, data []byte) {
+		if v, err := decodeViewingConditions(data); err == nil {
+			v2, err := decodeViewingConditions(v.encode())
+			if err != nil {
+				t.Fatalf("view: re-decoding failed: %v", err)
+			}
+			if *v2 != *v {
+				t.Fatalf("view round trip failed: %v %v", v, v2)
+			}
+		}
+		if c, err := decodeXYZ(data); err == nil {
+			c2, err := decodeXYZ(encodeXYZ(c))
+			if err != nil || c2 != c {
+				t.Fatalf("XYZ round trip failed: %v %v %v", c, c2, err)
+			}
+		}
+	})
+}
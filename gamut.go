@@ -0,0 +1,73 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "fmt"
+
+// RenderingIntentGamut identifies the gamut used by the perceptual or
+// saturation rendering intent of a profile.
+type RenderingIntentGamut uint32
+
+func (g RenderingIntentGamut) String() string {
+	switch g {
+	case PerceptualReferenceMediumGamut:
+		return "Perceptual Reference Medium Gamut"
+	default:
+		return fmt.Sprintf("RenderingIntentGamut(0x%08X)", uint32(g))
+	}
+}
+
+// Rendering intent gamuts defined in the ICC specification.
+const (
+	PerceptualReferenceMediumGamut RenderingIntentGamut = 0x70726D67 // "prmg"
+)
+
+// PerceptualRenderingIntentGamut returns the contents of the perceptual
+// rendering intent gamut tag.
+func (p *Profile) PerceptualRenderingIntentGamut() (RenderingIntentGamut, error) {
+	return p.getRenderingIntentGamut(PerceptualRenderingIntentGamut)
+}
+
+// SetPerceptualRenderingIntentGamut sets the perceptual rendering intent
+// gamut tag.
+func (p *Profile) SetPerceptualRenderingIntentGamut(g RenderingIntentGamut) {
+	p.SetTag(PerceptualRenderingIntentGamut, encodeSignature(uint32(g)))
+}
+
+// SaturationRenderingIntentGamut returns the contents of the saturation
+// rendering intent gamut tag.
+func (p *Profile) SaturationRenderingIntentGamut() (RenderingIntentGamut, error) {
+	return p.getRenderingIntentGamut(SaturationRenderingIntentGamut)
+}
+
+// SetSaturationRenderingIntentGamut sets the saturation rendering intent
+// gamut tag.
+func (p *Profile) SetSaturationRenderingIntentGamut(g RenderingIntentGamut) {
+	p.SetTag(SaturationRenderingIntentGamut, encodeSignature(uint32(g)))
+}
+
+func (p *Profile) getRenderingIntentGamut(tagType TagType) (RenderingIntentGamut, error) {
+	tag, ok := p.TagData[tagType]
+	if !ok {
+		return 0, errMissingTag
+	}
+	sig, err := decodeSignature(tag)
+	if err != nil {
+		return 0, err
+	}
+	return RenderingIntentGamut(sig), nil
+}
@@ -7,4 +7,5 @@ toolchain go1.23.4
 require (
 	github.com/google/go-cmp v0.6.0
 	golang.org/x/exp v0.0.0-20240409090435-93d18d7e34b8
+	golang.org/x/text v0.21.0
 )
@@ -39,7 +39,22 @@ type Profile struct {
 
 	CheckSum CheckSum
 
+	// Dirty reports whether the tags have been modified using SetTag or one
+	// of the other setter methods since the profile was decoded or since
+	// MarkClean was last called.  Callers which keep an encoded copy of the
+	// profile can use the flag to detect that the copy is stale.
+	// Modifications of TagData which bypass the setters are not tracked.
+	Dirty bool
+
 	TagData map[TagType][]byte
+
+	// TrailingData is written after the last tag, outside the profile
+	// size given in the header.  See DecodeOptions.KeepTrailingData.
+	TrailingData []byte
+
+	// Warnings lists the defects which were repaired when the profile was
+	// decoded in lenient mode.
+	Warnings []error
 }
 
 // Version is a version of the ICC profile format.
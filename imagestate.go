@@ -0,0 +1,97 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "fmt"
+
+// ImageState describes the image state of the PCS colorimetry produced
+// by the colorimetric intent transforms of a profile.
+type ImageState uint32
+
+func (s ImageState) String() string {
+	switch s {
+	case SceneColorimetryEstimates:
+		return "Scene Colorimetry Estimates"
+	case SceneAppearanceEstimates:
+		return "Scene Appearance Estimates"
+	case FocalPlaneColorimetryEstimates:
+		return "Focal Plane Colorimetry Estimates"
+	case ReflectionHardcopyOriginalColorimetry:
+		return "Reflection Hardcopy Original Colorimetry"
+	case ReflectionPrintOutputColorimetry:
+		return "Reflection Print Output Colorimetry"
+	default:
+		return fmt.Sprintf("ImageState(0x%08X)", uint32(s))
+	}
+}
+
+// IsSceneReferred returns true if the colorimetric intent transforms
+// produce estimates of the original scene, rather than of a hardcopy
+// original or print.
+//
+// For scene-referred data the media white point is not meaningful, and
+// the PCS values should not be adapted using the media white when
+// ICC-absolute colorimetry is requested.
+func (s ImageState) IsSceneReferred() bool {
+	switch s {
+	case SceneColorimetryEstimates, SceneAppearanceEstimates, FocalPlaneColorimetryEstimates:
+		return true
+	default:
+		return false
+	}
+}
+
+// Image states defined in the ICC specification.
+const (
+	SceneColorimetryEstimates             ImageState = 0x73636F65 // "scoe"
+	SceneAppearanceEstimates              ImageState = 0x73617065 // "sape"
+	FocalPlaneColorimetryEstimates        ImageState = 0x66706365 // "fpce"
+	ReflectionHardcopyOriginalColorimetry ImageState = 0x72686F63 // "rhoc"
+	ReflectionPrintOutputColorimetry      ImageState = 0x72706F63 // "rpoc"
+)
+
+// ColorimetricIntentImageState returns the contents of the colorimetric
+// intent image state tag.
+func (p *Profile) ColorimetricIntentImageState() (ImageState, error) {
+	tag, ok := p.TagData[ColorimetricIntentImageState]
+	if !ok {
+		return 0, errMissingTag
+	}
+	sig, err := decodeSignature(tag)
+	if err != nil {
+		return 0, err
+	}
+	return ImageState(sig), nil
+}
+
+// SetColorimetricIntentImageState sets the colorimetric intent image state
+// tag.
+func (p *Profile) SetColorimetricIntentImageState(s ImageState) {
+	p.SetTag(ColorimetricIntentImageState, encodeSignature(uint32(s)))
+}
+
+// UseMediaWhiteForAbsolute returns true if ICC-absolute colorimetry for
+// this profile should be computed by scaling the PCS values with the media
+// white point.  This is the case unless the colorimetric intent image state
+// tag marks the PCS data as scene-referred.
+func (p *Profile) UseMediaWhiteForAbsolute() bool {
+	s, err := p.ColorimetricIntentImageState()
+	if err != nil {
+		return true
+	}
+	return !s.IsSceneReferred()
+}
@@ -0,0 +1,71 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"fmt"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+// LanguageTag returns the BCP 47 language tag corresponding to the
+// language and country codes of the record.
+func (lu LocalizedUnicode) LanguageTag() language.Tag {
+	lang := strings.Trim(lu.Language, "\000 ")
+	country := strings.Trim(lu.Country, "\000 ")
+	if country != "" {
+		lang += "-" + country
+	}
+	tag, err := language.Parse(lang)
+	if err != nil {
+		return language.Und
+	}
+	return tag
+}
+
+// NewLocalizedUnicode creates a new record for the given BCP 47 language tag.
+// If the tag does not specify a region, the most likely region for the
+// language is used.  An error is returned if the language has no
+// two-letter ISO 639-1 code, or the region has no two-letter ISO 3166-1
+// code.
+func NewLocalizedUnicode(tag language.Tag, value string) (LocalizedUnicode, error) {
+	base, _ := tag.Base()
+	region, _ := tag.Region()
+
+	lang := base.String()
+	country := region.String()
+	if len(lang) != 2 || len(country) != 2 || !region.IsCountry() {
+		return LocalizedUnicode{}, fmt.Errorf("icc: language tag %s cannot be represented", tag)
+	}
+	res := LocalizedUnicode{
+		Language: lang,
+		Country:  country,
+		Value:    value,
+	}
+	return res, nil
+}
+
+// Tags returns the BCP 47 language tags of all records, in order.
+// The result can be used to construct a language.Matcher.
+func (m MultiLocalizedUnicode) Tags() []language.Tag {
+	res := make([]language.Tag, len(m))
+	for i, lu := range m {
+		res[i] = lu.LanguageTag()
+	}
+	return res
+}
@@ -0,0 +1,59 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "math"
+
+// matrix3 is a 3x3 matrix, stored in row-major order.
+type matrix3 [9]float64
+
+// apply computes the product of m and the column vector v.
+func (m *matrix3) apply(v [3]float64) [3]float64 {
+	return [3]float64{
+		m[0]*v[0] + m[1]*v[1] + m[2]*v[2],
+		m[3]*v[0] + m[4]*v[1] + m[5]*v[2],
+		m[6]*v[0] + m[7]*v[1] + m[8]*v[2],
+	}
+}
+
+// mul computes the matrix product m*n.
+func (m *matrix3) mul(n *matrix3) *matrix3 {
+	res := &matrix3{}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			res[3*i+j] = m[3*i]*n[j] + m[3*i+1]*n[3+j] + m[3*i+2]*n[6+j]
+		}
+	}
+	return res
+}
+
+// inv computes the inverse of m.  If m is singular, or if the determinant
+// is not a finite number, nil is returned.
+func (m *matrix3) inv() *matrix3 {
+	c0 := m[4]*m[8] - m[5]*m[7]
+	c1 := m[5]*m[6] - m[3]*m[8]
+	c2 := m[3]*m[7] - m[4]*m[6]
+	det := m[0]*c0 + m[1]*c1 + m[2]*c2
+	if det == 0 || math.IsNaN(det) || math.IsInf(det, 0) {
+		return nil
+	}
+	return &matrix3{
+		c0 / det, (m[2]*m[7] - m[1]*m[8]) / det, (m[1]*m[5] - m[2]*m[4]) / det,
+		c1 / det, (m[0]*m[8] - m[2]*m[6]) / det, (m[2]*m[3] - m[0]*m[5]) / det,
+		c2 / det, (m[1]*m[6] - m[0]*m[7]) / det, (m[0]*m[4] - m[1]*m[3]) / det,
+	}
+}
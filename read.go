@@ -26,6 +26,36 @@ import (
 // Decode decodes an ICC profile from the given data.
 // The function takes over ownership of the data.
 func Decode(data []byte) (*Profile, error) {
+	return DecodeWithOptions(data, nil)
+}
+
+// DecodeOptions controls how DecodeWithOptions handles malformed profiles.
+type DecodeOptions struct {
+	// Lenient enables repairs of common defects found in real-world
+	// profiles, instead of rejecting the profile.  Each repair is recorded
+	// in the Warnings field of the returned Profile.
+	//
+	// Currently, tags which extend beyond the end of the data are
+	// truncated, and malformed strings in multiLocalizedUnicodeType
+	// elements are repaired.
+	Lenient bool
+
+	// KeepTrailingData causes data after the end of the last tag to be
+	// stored in the TrailingData field of the returned Profile, so that
+	// it is written back by Encode.  Some vendors append private data
+	// there.  Trailing data which consists only of zero bytes is treated
+	// as padding and is not kept.
+	KeepTrailingData bool
+}
+
+// DecodeWithOptions decodes an ICC profile from the given data, using the
+// given options.  If opt is nil, the function behaves like Decode.
+// The function takes over ownership of the data.
+func DecodeWithOptions(data []byte, opt *DecodeOptions) (*Profile, error) {
+	if opt == nil {
+		opt = &DecodeOptions{}
+	}
+
 	if len(data) < 128+4 {
 		return nil, invalidProfile(0, "profile is too short")
 	}
@@ -44,23 +74,7 @@ func Decode(data []byte) (*Profile, error) {
 	// 	return nil, invalidProfile(68, "missing 'D50 ' signature")
 	// }
 
-	p := &Profile{
-		PreferedCMMType:    getUint32(data, 4),
-		Version:            Version(getUint32(data, 8)),
-		Class:              ProfileClass(getUint32(data, 12)),
-		ColorSpace:         ColorSpace(getUint32(data, 16)),
-		PCS:                ColorSpace(getUint32(data, 20)),
-		CreationDate:       getDateTime(data, 24),
-		PrimaryPlatform:    getUint32(data, 40),
-		Flags:              getUint32(data, 44),
-		DeviceManufacturer: getUint32(data, 48),
-		DeviceModel:        getUint32(data, 52),
-		DeviceAttributes:   getUint64(data, 56),
-		RenderingIntent:    RenderingIntent(getUint32(data, 64)),
-		Creator:            getUint32(data, 80),
-
-		TagData: make(map[TagType][]byte),
-	}
+	p := decodeHeader(data)
 
 	if !isZero(data[84:100]) {
 		var givenHash [16]byte
@@ -76,7 +90,13 @@ func Decode(data []byte) (*Profile, error) {
 			data[i] = 0
 		}
 
-		computedHash := md5.Sum(data)
+		// If the size field is valid, data beyond the declared size is not
+		// part of the profile.
+		hashed := data
+		if size := getUint32(data, 0); size >= 128 && uint64(size) <= uint64(len(data)) {
+			hashed = data[:size]
+		}
+		computedHash := md5.Sum(hashed)
 		if bytes.Equal(computedHash[:], givenHash[:]) {
 			p.CheckSum = CheckSumValid
 		} else {
@@ -85,6 +105,7 @@ func Decode(data []byte) (*Profile, error) {
 	}
 
 	minTagOffset := 128 + 4 + int64(numTags)*12
+	dataEnd := minTagOffset
 	for i := 0; i < int(numTags); i++ {
 		offset := 128 + 4 + i*12
 		tagType := TagType(getUint32(data, offset))
@@ -98,10 +119,39 @@ func Decode(data []byte) (*Profile, error) {
 
 		start := int64(tagOffset)
 		end := start + int64(tagSize)
+		if opt.Lenient && end > int64(len(data)) && start+4 <= int64(len(data)) {
+			p.Warnings = append(p.Warnings,
+				invalidProfile(offset+8, fmt.Sprintf("tag %s truncated by %d bytes", tagType, end-int64(len(data)))))
+			end = int64(len(data))
+		}
 		if start < minTagOffset || end > int64(len(data)) {
 			return nil, invalidProfile(offset, "tag is out of bounds")
 		}
 		p.TagData[tagType] = data[start:end]
+		dataEnd = max(dataEnd, end)
+
+		if opt.Lenient && string(data[start:start+4]) == "mluc" {
+			_, err := decodeMLUC(data[start:end], false)
+			if err == errInvalidTagData {
+				var repaired []byte
+				m, err := decodeMLUC(data[start:end], true)
+				if err == nil {
+					repaired, err = m.Encode()
+				}
+				if err == nil {
+					p.TagData[tagType] = repaired
+					p.Warnings = append(p.Warnings,
+						invalidProfile(offset, fmt.Sprintf("malformed strings in tag %s repaired", tagType)))
+				}
+			}
+		}
+	}
+
+	if opt.KeepTrailingData {
+		dataEnd = min((dataEnd+3)&^3, int64(len(data)))
+		if !isZero(data[dataEnd:]) {
+			p.TrailingData = data[dataEnd:]
+		}
 	}
 
 	if p.Version == 0 {
@@ -111,6 +161,29 @@ func Decode(data []byte) (*Profile, error) {
 	return p, nil
 }
 
+// decodeHeader decodes the fields of the 128-byte profile header.
+// The profile ID is not checked.
+func decodeHeader(data []byte) *Profile {
+	p := &Profile{
+		PreferedCMMType:    getUint32(data, 4),
+		Version:            Version(getUint32(data, 8)),
+		Class:              ProfileClass(getUint32(data, 12)),
+		ColorSpace:         ColorSpace(getUint32(data, 16)),
+		PCS:                ColorSpace(getUint32(data, 20)),
+		CreationDate:       getDateTime(data, 24),
+		PrimaryPlatform:    getUint32(data, 40),
+		Flags:              getUint32(data, 44),
+		DeviceManufacturer: getUint32(data, 48),
+		DeviceModel:        getUint32(data, 52),
+		DeviceAttributes:   getUint64(data, 56),
+		RenderingIntent:    RenderingIntent(getUint32(data, 64)),
+		Creator:            getUint32(data, 80),
+
+		TagData: make(map[TagType][]byte),
+	}
+	return p
+}
+
 func isZero(b []byte) bool {
 	for _, x := range b {
 		if x != 0 {
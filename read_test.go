@@ -17,7 +17,9 @@
 package icc
 
 import (
+	"bytes"
 	"fmt"
+	"io"
 	"reflect"
 	"testing"
 	"time"
@@ -71,3 +73,191 @@ func FuzzDecode(f *testing.F) {
 		}
 	})
 }
+
+func TestDecodeLenient(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.TagData[Copyright] = []byte("text\000\000\000\000Public Domain")
+	data := p.Encode()
+	data = data[:len(data)-4] // cut into the padding and the tag data
+
+	_, err := Decode(bytes.Clone(data))
+	if err == nil {
+		t.Fatal("truncated tag not detected")
+	}
+
+	q, err := DecodeWithOptions(data, &DecodeOptions{Lenient: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(q.Warnings) != 1 {
+		t.Errorf("got %d warnings, want 1", len(q.Warnings))
+	}
+	want := "text\000\000\000\000Public Domai"
+	if got := string(q.TagData[Copyright]); got != want {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func TestDecodeAt(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetCopyright("Public Domain")
+	p.SetDescription("Test")
+	p.TagData[0x61616161] = p.TagData[Copyright]
+	data := p.Encode()
+
+	lp, err := DecodeAt(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := len(lp.Tags()); n != 3 {
+		t.Errorf("got %d tags, want 3", n)
+	}
+	if len(lp.TagData) != 0 {
+		t.Errorf("tag data read too early")
+	}
+
+	_, err = lp.ReadTag(Copyright)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cprt, err := lp.Copyright()
+	if err != nil || cprt.Lookup("en", "US") != "Public Domain" {
+		t.Errorf("wrong copyright: %v %v", cprt, err)
+	}
+
+	q, err := lp.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(q.Encode(), data) {
+		t.Errorf("re-encoded profile differs")
+	}
+}
+
+// eofReaderAt returns io.EOF together with the data when a read ends at
+// the end of the input, as permitted by the io.ReaderAt interface.
+type eofReaderAt []byte
+
+func (r eofReaderAt) ReadAt(buf []byte, off int64) (int, error) {
+	if off >= int64(len(r)) {
+		return 0, io.EOF
+	}
+	n := copy(buf, r[off:])
+	if off+int64(n) == int64(len(r)) {
+		return n, io.EOF
+	}
+	return n, nil
+}
+
+func TestDecodeAtEOF(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetCopyright("Public Domain.") // 56 bytes, no padding
+	data := p.Encode()
+
+	lp, err := DecodeAt(eofReaderAt(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = lp.Load() // the copyright tag ends at the end of data
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// truncated input must still be rejected
+	lp, err = DecodeAt(eofReaderAt(data[:len(data)-4]), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := lp.Load(); err != io.ErrUnexpectedEOF {
+		t.Errorf("got %v, want io.ErrUnexpectedEOF", err)
+	}
+}
+
+// countingReaderAt counts the calls to ReadAt.
+type countingReaderAt struct {
+	r     io.ReaderAt
+	calls int
+}
+
+func (c *countingReaderAt) ReadAt(buf []byte, off int64) (int, error) {
+	c.calls++
+	return c.r.ReadAt(buf, off)
+}
+
+func TestDecodeAtSharedTags(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetCopyright("Public Domain")
+	for tagType := TagType(0x61616161); tagType < 0x61616161+100; tagType++ {
+		p.TagData[tagType] = p.TagData[Copyright]
+	}
+	data := p.Encode()
+
+	r := &countingReaderAt{r: bytes.NewReader(data)}
+	lp, err := DecodeAt(r, int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.calls = 0
+	q, err := lp.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.calls != 1 {
+		t.Errorf("shared data read %d times", r.calls)
+	}
+	if len(q.TagData) != 101 {
+		t.Errorf("got %d tags, want 101", len(q.TagData))
+	}
+}
+
+func TestDecodeAtSize(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetCopyright("Public Domain")
+	data := p.Encode()
+
+	// the header claims more data than is available
+	_, err := DecodeAt(bytes.NewReader(data), int64(len(data)-1))
+	if err == nil {
+		t.Error("truncated profile not detected")
+	}
+
+	bad := bytes.Clone(data)
+	putUint32(bad, 0, 100)
+	_, err = DecodeAt(bytes.NewReader(bad), int64(len(bad)))
+	if err == nil {
+		t.Error("invalid profile size not detected")
+	}
+}
+
+func TestDecodeTrailingData(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetCopyright("Public Domain")
+	data := p.Encode()
+	vendorData := []byte("private calibration data")
+	data = append(data, vendorData...)
+
+	q, err := Decode(bytes.Clone(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if q.TrailingData != nil {
+		t.Error("trailing data kept without option")
+	}
+	if q.CheckSum != CheckSumValid {
+		t.Errorf("wrong checksum status %s", q.CheckSum)
+	}
+
+	q, err = DecodeWithOptions(bytes.Clone(data), &DecodeOptions{KeepTrailingData: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(q.TrailingData, vendorData) {
+		t.Errorf("wrong trailing data %q", q.TrailingData)
+	}
+	if out := q.Encode(); !bytes.Equal(out, data) {
+		t.Error("trailing data not preserved")
+	}
+	if q.EncodedSize() != len(data) {
+		t.Errorf("wrong encoded size %d, want %d", q.EncodedSize(), len(data))
+	}
+}
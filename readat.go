@@ -0,0 +1,184 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"cmp"
+	"io"
+	"slices"
+)
+
+// LazyProfile is an ICC profile where the tag data is read on demand.
+//
+// The embedded Profile contains the header fields.  Its TagData map
+// only contains the tags which have been read using ReadTag or Load,
+// and the Profile accessor methods can be used for these tags.
+// Since not all bytes of the profile are read, the profile ID is not
+// verified and CheckSum is always CheckSumMissing.
+type LazyProfile struct {
+	*Profile
+
+	r    io.ReaderAt
+	tags map[TagType]tagLocation
+
+	// loaded maps tag locations to the data read so far, so that tags
+	// which share data are read only once.
+	loaded map[tagLocation][]byte
+}
+
+type tagLocation struct {
+	start, size int64
+}
+
+// DecodeAt reads the header and tag table of an ICC profile from r.
+// The size argument gives the total length of the data available in r.
+// An error is returned if the profile size given in the header exceeds
+// this length.  Tag data is read only when requested.
+func DecodeAt(r io.ReaderAt, size int64) (*LazyProfile, error) {
+	if size < 128+4 {
+		return nil, invalidProfile(0, "profile is too short")
+	}
+	header := make([]byte, 128+4)
+	err := readFullAt(r, header, 0)
+	if err != nil {
+		return nil, err
+	}
+	if string(header[36:40]) != "acsp" {
+		return nil, invalidProfile(36, "missing 'acsp' signature")
+	}
+	declaredSize := int64(getUint32(header, 0))
+	if declaredSize < 128+4 {
+		return nil, invalidProfile(0, "invalid profile size")
+	} else if declaredSize > size {
+		return nil, invalidProfile(0, "profile is truncated")
+	}
+
+	numTags := getUint32(header, 128)
+	maxNumTags := uint64((size - 128 - 4) / 12)
+	if uint64(numTags) > maxNumTags {
+		return nil, invalidProfile(128, "too many tags")
+	}
+	tagTable := make([]byte, 12*int64(numTags))
+	err = readFullAt(r, tagTable, 128+4)
+	if err != nil {
+		return nil, err
+	}
+
+	p := decodeHeader(header)
+	if p.Version == 0 {
+		p.Version = currentVersion
+	}
+	lp := &LazyProfile{
+		Profile: p,
+		r:       r,
+		tags:    make(map[TagType]tagLocation, numTags),
+		loaded:  make(map[tagLocation][]byte),
+	}
+
+	minTagOffset := 128 + 4 + int64(numTags)*12
+	for i := 0; i < int(numTags); i++ {
+		offset := 12 * i
+		tagType := TagType(getUint32(tagTable, offset))
+		tagOffset := getUint32(tagTable, offset+4)
+		tagSize := getUint32(tagTable, offset+8)
+		if tagSize < 4 {
+			return nil, invalidProfile(128+4+offset+8, "tag is too small")
+		} else if tagSize > 0xFFFFFFFC {
+			return nil, invalidProfile(128+4+offset+8, "tag is too large")
+		}
+
+		start := int64(tagOffset)
+		end := start + int64(tagSize)
+		if start < minTagOffset || end > size {
+			return nil, invalidProfile(128+4+offset, "tag is out of bounds")
+		}
+		lp.tags[tagType] = tagLocation{start: start, size: int64(tagSize)}
+	}
+
+	return lp, nil
+}
+
+// Tags returns the tags present in the profile, in increasing order.
+func (lp *LazyProfile) Tags() []TagType {
+	res := make([]TagType, 0, len(lp.tags))
+	for tagType := range lp.tags {
+		res = append(res, tagType)
+	}
+	slices.Sort(res)
+	return res
+}
+
+// ReadTag returns the data for the given tag, reading it from the
+// underlying reader if necessary.  The data is also stored in TagData.
+func (lp *LazyProfile) ReadTag(tagType TagType) ([]byte, error) {
+	if data, ok := lp.TagData[tagType]; ok {
+		return data, nil
+	}
+	loc, ok := lp.tags[tagType]
+	if !ok {
+		return nil, errMissingTag
+	}
+
+	// Tags may share data.  Avoid reading the same bytes twice.
+	data, ok := lp.loaded[loc]
+	if !ok {
+		data = make([]byte, loc.size)
+		err := readFullAt(lp.r, data, loc.start)
+		if err != nil {
+			return nil, err
+		}
+		lp.loaded[loc] = data
+	}
+	lp.TagData[tagType] = data
+	return data, nil
+}
+
+// Load reads all remaining tags and returns the complete profile.
+// The tags are read in the order in which they are stored.
+func (lp *LazyProfile) Load() (*Profile, error) {
+	tags := make([]TagType, 0, len(lp.tags))
+	for tagType := range lp.tags {
+		tags = append(tags, tagType)
+	}
+	slices.SortFunc(tags, func(a, b TagType) int {
+		return cmp.Or(
+			cmp.Compare(lp.tags[a].start, lp.tags[b].start),
+			cmp.Compare(a, b),
+		)
+	})
+	for _, tagType := range tags {
+		_, err := lp.ReadTag(tagType)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return lp.Profile, nil
+}
+
+// readFullAt reads len(buf) bytes from r, starting at offset off.
+// Implementations of io.ReaderAt may return io.EOF together with a
+// complete read, if the read ends at the end of the input.  This case
+// is not treated as an error.
+func readFullAt(r io.ReaderAt, buf []byte, off int64) error {
+	n, err := r.ReadAt(buf, off)
+	if n == len(buf) {
+		return nil
+	} else if err == nil || err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
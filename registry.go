@@ -0,0 +1,88 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"bytes"
+	"sync"
+	"time"
+)
+
+// RegisterProfile makes a profile available under the given name, for use
+// with LookupProfile.  If a profile with the same name is already
+// registered, it is replaced.  The profile is encoded when it is
+// registered, so later changes to p do not affect the registry.
+// Trailing data is kept, unless it consists only of zero bytes.
+//
+// The name "sRGB" is pre-registered with a version 4 sRGB display profile.
+// This profile is only built when it is first looked up.
+func RegisterProfile(name string, p *Profile) {
+	data := p.Encode()
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+	registry[name] = data
+}
+
+// LookupProfile returns a copy of the profile registered under the given
+// name.  Each call returns a new Profile, which the caller may modify.
+// If no such profile exists, nil is returned.
+// It is safe to call LookupProfile from multiple goroutines.
+func LookupProfile(name string) *Profile {
+	registryMutex.RLock()
+	data, ok := registry[name]
+	registryMutex.RUnlock()
+	if !ok && name == "sRGB" {
+		data, ok = builtinSRGB(), true
+	}
+	if !ok {
+		return nil
+	}
+
+	// Decode modifies its argument, so we need to work on a copy.
+	opt := &DecodeOptions{KeepTrailingData: true}
+	p, err := DecodeWithOptions(bytes.Clone(data), opt)
+	if err != nil {
+		panic(err) // unreachable, since data was produced by Encode
+	}
+	return p
+}
+
+var (
+	registryMutex sync.RWMutex
+	registry      = map[string][]byte{}
+)
+
+// builtinSRGB returns the encoded built-in sRGB profile.
+var builtinSRGB = sync.OnceValue(func() []byte {
+	return newSRGBProfile().Encode()
+})
+
+// newSRGBProfile creates the built-in sRGB profile.  The creation date is
+// fixed, so that the encoded profile and its profile ID do not change
+// between runs.
+func newSRGBProfile() *Profile {
+	p, err := NewRGBProfile(SRGBPrimaries, WhitePoint(IlluminantD65, Observer2), SRGBCurve(), &ProfileOptions{
+		Description:  "sRGB IEC61966-2.1",
+		Copyright:    "No copyright, use freely",
+		CreationDate: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
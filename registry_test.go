@@ -0,0 +1,97 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestRegistry(t *testing.T) {
+	srgb := LookupProfile("sRGB")
+	if srgb == nil {
+		t.Fatal("sRGB profile not registered")
+	}
+	if desc, _ := srgb.Description(); desc.Lookup("en", "US") != "sRGB IEC61966-2.1" {
+		t.Errorf("wrong description %v", desc)
+	}
+	if LookupProfile("test/missing") != nil {
+		t.Error("unexpected profile")
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			RegisterProfile("test/example", &Profile{Class: DisplayDeviceProfile})
+			p := LookupProfile("sRGB")
+			p.SetDescription("modified")
+			_ = p.Encode()
+		}()
+	}
+	wg.Wait()
+	if p := LookupProfile("test/example"); p == nil || p.Class != DisplayDeviceProfile {
+		t.Error("registered profile not found")
+	}
+}
+
+func TestRegistryCopies(t *testing.T) {
+	p := LookupProfile("sRGB")
+	p.SetDescription("modified")
+	delete(p.TagData, RedTRC)
+
+	q := LookupProfile("sRGB")
+	if desc, _ := q.Description(); desc.Lookup("en", "US") != "sRGB IEC61966-2.1" {
+		t.Errorf("registry modified through returned profile: %v", desc)
+	}
+	if _, ok := q.TagData[RedTRC]; !ok {
+		t.Error("tag deleted from registry")
+	}
+
+	orig := &Profile{
+		Class:        OutputDeviceProfile,
+		TagData:      make(map[TagType][]byte),
+		TrailingData: []byte("vendor data"),
+	}
+	RegisterProfile("test/snapshot", orig)
+	orig.Class = InputDeviceProfile
+	p = LookupProfile("test/snapshot")
+	if p.Class != OutputDeviceProfile {
+		t.Error("registry modified through registered profile")
+	}
+	if string(p.TrailingData) != "vendor data" {
+		t.Errorf("trailing data not kept: %q", p.TrailingData)
+	}
+}
+
+func TestBuiltinSRGBReproducible(t *testing.T) {
+	data1 := newSRGBProfile().Encode()
+	data2 := newSRGBProfile().Encode()
+	if !bytes.Equal(data1, data2) {
+		t.Error("built-in sRGB profile is not reproducible")
+	}
+	if !bytes.Equal(builtinSRGB(), data1) {
+		t.Error("wrong built-in sRGB profile")
+	}
+	want := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	if p := LookupProfile("sRGB"); !p.CreationDate.Equal(want) {
+		t.Errorf("creation date %s, want %s", p.CreationDate, want)
+	}
+}
@@ -0,0 +1,95 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "time"
+
+// Repair fixes common defects found in real-world profiles.
+// The return value lists the fixes which were applied.
+//
+// The following problems are fixed:
+//   - A missing or invalid creation date is replaced by the current time.
+//   - Missing description and copyright tags are replaced by placeholders.
+//   - An invalid profile ID is marked for recomputation.
+//   - For DeviceLink profiles, a PCS field which does not match the number
+//     of output channels of the AToB0 tag is replaced by a generic color
+//     space with the correct number of channels.
+//
+// The profile size field does not need repairing, since Encode always
+// computes it from the profile contents.
+func (p *Profile) Repair() []string {
+	var fixes []string
+
+	if p.CheckSum == CheckSumInvalid {
+		// Encode always computes a new profile ID.
+		p.CheckSum = CheckSumMissing
+		fixes = append(fixes, "profile ID will be recomputed")
+	}
+
+	if p.CreationDate.IsZero() {
+		p.CreationDate = time.Now().UTC().Truncate(time.Second)
+		fixes = append(fixes, "set missing creation date")
+	}
+
+	if _, ok := p.TagData[ProfileDescription]; !ok {
+		p.SetDescription("Unnamed Profile")
+		fixes = append(fixes, "added placeholder description tag")
+	}
+	if _, ok := p.TagData[Copyright]; !ok {
+		p.SetCopyright("No copyright information available")
+		fixes = append(fixes, "added placeholder copyright tag")
+	}
+
+	if p.Class == DeviceLinkProfile {
+		n := lutOutputChannels(p.TagData[AToB0])
+		if space := genericColorSpace(n); space != 0 && p.PCS.NumComponents() != n {
+			p.PCS = space
+			fixes = append(fixes, "set PCS field to "+p.PCS.String())
+		}
+	}
+
+	return fixes
+}
+
+// lutOutputChannels returns the number of output channels of a lut8Type,
+// lut16Type or lutAToBType element.  If the number cannot be determined,
+// 0 is returned.
+func lutOutputChannels(data []byte) int {
+	if len(data) < 12 {
+		return 0
+	}
+	switch string(data[:4]) {
+	case "mft1", "mft2", "mAB ":
+		return int(data[9])
+	default:
+		return 0
+	}
+}
+
+// genericColorSpace returns a color space with n components.
+func genericColorSpace(n int) ColorSpace {
+	switch {
+	case n == 1:
+		return GraySpace
+	case n >= 2 && n <= 9:
+		return ColorSpace(0x30434C52 + uint32(n)<<24)
+	case n >= 10 && n <= 15:
+		return ColorSpace(0x41434C52 + uint32(n-10)<<24)
+	default:
+		return 0
+	}
+}
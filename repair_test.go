@@ -0,0 +1,50 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "testing"
+
+func TestRepair(t *testing.T) {
+	lut := make([]byte, 48)
+	copy(lut, "mft2")
+	lut[8] = 3 // input channels
+	lut[9] = 4 // output channels
+
+	p := &Profile{
+		Version:  Version2_1_0,
+		Class:    DeviceLinkProfile,
+		PCS:      PCSLabSpace,
+		CheckSum: CheckSumInvalid,
+		TagData:  map[TagType][]byte{AToB0: lut},
+	}
+	fixes := p.Repair()
+	if len(fixes) != 5 {
+		t.Errorf("got %d fixes, want 5: %q", len(fixes), fixes)
+	}
+	if p.PCS != Color4Space {
+		t.Errorf("got PCS %s, want %s", p.PCS, Color4Space)
+	}
+	cprt, err := p.Copyright()
+	if err != nil || len(cprt) != 1 {
+		t.Errorf("invalid copyright tag: %v", err)
+	}
+
+	fixes = p.Repair()
+	if len(fixes) != 0 {
+		t.Errorf("second call applied fixes: %q", fixes)
+	}
+}
@@ -0,0 +1,226 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"fmt"
+	"math"
+)
+
+// Spectrum gives a spectral quantity as a function of the wavelength in
+// nanometres.
+type Spectrum func(lambda float64) float64
+
+// ColorMatchingFunctions gives the color matching functions of an observer
+// as a function of the wavelength in nanometres.
+type ColorMatchingFunctions func(lambda float64) (x, y, z float64)
+
+// CIE1931 gives the color matching functions of the CIE 1931 2° standard
+// observer, linearly interpolated from the 5nm table in CIE 15:2004.  The
+// functions are zero outside the range 380nm to 780nm.
+func CIE1931(lambda float64) (x, y, z float64) {
+	return interpolateCMF(&cie1931, lambda)
+}
+
+// CIE1964 gives the color matching functions of the CIE 1964 10°
+// supplementary standard observer, linearly interpolated from the 5nm table
+// in CIE 15:2004.  The functions are zero outside the range 380nm to 780nm.
+func CIE1964(lambda float64) (x, y, z float64) {
+	return interpolateCMF(&cie1964, lambda)
+}
+
+func interpolateCMF(table *[81][3]float64, lambda float64) (x, y, z float64) {
+	pos := (lambda - 380) / 5
+	if !(pos >= 0 && pos <= 80) {
+		return 0, 0, 0
+	}
+	i := min(int(pos), 79)
+	t := pos - float64(i)
+	a, b := table[i], table[i+1]
+	return a[0] + t*(b[0]-a[0]), a[1] + t*(b[1]-a[1]), a[2] + t*(b[2]-a[2])
+}
+
+// CMF returns the color matching functions of the observer.
+// The function panics if the observer is not known.
+func (o Observer) CMF() ColorMatchingFunctions {
+	switch o {
+	case Observer2:
+		return CIE1931
+	case Observer10:
+		return CIE1964
+	default:
+		panic(fmt.Sprintf("icc: unknown observer %s", o))
+	}
+}
+
+// CIE1931Approx approximates the color matching functions of the CIE 1931
+// 2° standard observer, using the multi-lobe fit from Wyman, Sloan and
+// Shirley, "Simple Analytic Approximations to the CIE XYZ Color Matching
+// Functions", JCGT 2(2), 2013.  The error is small compared to typical
+// measurement noise, but users who need exact agreement with CIE
+// colorimetry should supply the tabulated functions instead.
+func CIE1931Approx(lambda float64) (x, y, z float64) {
+	x = 1.056*lobe(lambda, 599.8, 37.9, 31.0) +
+		0.362*lobe(lambda, 442.0, 16.0, 26.7) -
+		0.065*lobe(lambda, 501.1, 20.4, 26.2)
+	y = 0.821*lobe(lambda, 568.8, 46.9, 40.5) +
+		0.286*lobe(lambda, 530.9, 16.3, 31.1)
+	z = 1.217*lobe(lambda, 437.0, 11.8, 36.0) +
+		0.681*lobe(lambda, 459.0, 26.0, 13.8)
+	return x, y, z
+}
+
+// lobe is a Gaussian with different widths left and right of the mean.
+func lobe(lambda, mu, sigma1, sigma2 float64) float64 {
+	sigma := sigma2
+	if lambda < mu {
+		sigma = sigma1
+	}
+	t := (lambda - mu) / sigma
+	return math.Exp(-0.5 * t * t)
+}
+
+// SPDIlluminantA is the relative spectral power distribution of CIE standard
+// illuminant A, as defined in CIE 15:2004, normalised to 100 at 560nm.
+func SPDIlluminantA(lambda float64) float64 {
+	const c2 = 1.435e7 // nm K
+	const T = 2848
+	return 100 * math.Pow(560/lambda, 5) *
+		math.Expm1(c2/(T*560)) / math.Expm1(c2/(T*lambda))
+}
+
+// SPDIlluminantE is the equal-energy illuminant.
+func SPDIlluminantE(lambda float64) float64 {
+	return 100
+}
+
+// SPDBlackbody returns the spectral power distribution of a Planckian
+// radiator at temperature T (in Kelvin), normalised to 100 at 560nm.
+func SPDBlackbody(T float64) Spectrum {
+	const c2 = 1.4388e7 // nm K
+	return func(lambda float64) float64 {
+		return 100 * math.Pow(560/lambda, 5) *
+			math.Expm1(c2/(T*560)) / math.Expm1(c2/(T*lambda))
+	}
+}
+
+// SPDIlluminantD50 is the relative spectral power distribution of CIE
+// illuminant D50, normalised to 100 at 560nm.  The function is zero
+// outside the range 380nm to 780nm.
+var SPDIlluminantD50 = daylight(5000*1.4388/1.4380, true)
+
+// SPDIlluminantD65 is the relative spectral power distribution of CIE
+// standard illuminant D65, normalised to 100 at 560nm.  The function is
+// zero outside the range 380nm to 780nm.
+var SPDIlluminantD65 = daylight(6500*1.4388/1.4380, true)
+
+// SPDDaylight returns the relative spectral power distribution of the CIE
+// daylight illuminant with correlated color temperature T (in Kelvin),
+// normalised to 100 at 560nm.  The method is defined for temperatures from
+// 4000K to 25000K.  The function is zero outside the range 380nm to 780nm.
+func SPDDaylight(T float64) Spectrum {
+	return daylight(T, false)
+}
+
+// daylight implements the method from CIE 15:2004, section 3.1.  For the
+// CIE standard illuminants, the coefficients M1 and M2 are rounded to
+// three decimals.
+func daylight(T float64, round bool) Spectrum {
+	var x float64
+	if T <= 7000 {
+		x = -4.6070e9/(T*T*T) + 2.9678e6/(T*T) + 0.09911e3/T + 0.244063
+	} else {
+		x = -2.0064e9/(T*T*T) + 1.9018e6/(T*T) + 0.24748e3/T + 0.237040
+	}
+	y := -3*x*x + 2.870*x - 0.275
+	m := 0.0241 + 0.2562*x - 0.7341*y
+	m1 := (-1.3515 - 1.7703*x + 5.9114*y) / m
+	m2 := (0.0300 - 31.4424*x + 30.0717*y) / m
+	if round {
+		m1 = math.Round(m1*1000) / 1000
+		m2 = math.Round(m2*1000) / 1000
+	}
+	return func(lambda float64) float64 {
+		pos := (lambda - 380) / 10
+		if !(pos >= 0 && pos <= 40) {
+			return 0
+		}
+		i := min(int(pos), 39)
+		t := pos - float64(i)
+		a, b := daylightS[i], daylightS[i+1]
+		s0 := a[0] + t*(b[0]-a[0])
+		s1 := a[1] + t*(b[1]-a[1])
+		s2 := a[2] + t*(b[2]-a[2])
+		return s0 + m1*s1 + m2*s2
+	}
+}
+
+// SPD returns the relative spectral power distribution of the illuminant.
+// The function panics if the illuminant is not known.
+func (ill Illuminant) SPD() Spectrum {
+	switch ill {
+	case IlluminantD50:
+		return SPDIlluminantD50
+	case IlluminantD65:
+		return SPDIlluminantD65
+	default:
+		panic(fmt.Sprintf("icc: unknown illuminant %s", ill))
+	}
+}
+
+// The wavelength range and step size used for integration.
+const (
+	spectralMin  = 360
+	spectralMax  = 830
+	spectralStep = 1
+)
+
+// SpectrumToXYZ computes the tristimulus values of a light source with the
+// given spectral power distribution, without normalisation.
+func SpectrumToXYZ(s Spectrum, cmf ColorMatchingFunctions) XYZ {
+	var res XYZ
+	for lambda := float64(spectralMin); lambda <= spectralMax; lambda += spectralStep {
+		p := s(lambda)
+		x, y, z := cmf(lambda)
+		res[0] += p * x * spectralStep
+		res[1] += p * y * spectralStep
+		res[2] += p * z * spectralStep
+	}
+	return res
+}
+
+// ReflectanceToXYZ computes the tristimulus values of a surface with the
+// given spectral reflectance (in the range [0, 1]), viewed under the given
+// illuminant.  The values are normalised so that the perfect reflecting
+// diffuser has Y=1.
+func ReflectanceToXYZ(reflectance, illuminant Spectrum, cmf ColorMatchingFunctions) XYZ {
+	var res XYZ
+	var norm float64
+	for lambda := float64(spectralMin); lambda <= spectralMax; lambda += spectralStep {
+		p := illuminant(lambda)
+		r := reflectance(lambda)
+		x, y, z := cmf(lambda)
+		res[0] += r * p * x
+		res[1] += r * p * y
+		res[2] += r * p * z
+		norm += p * y
+	}
+	for i := range res {
+		res[i] /= norm
+	}
+	return res
+}
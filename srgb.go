@@ -0,0 +1,67 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "math"
+
+// srgbToPCS maps linear sRGB values to PCS XYZ.  The columns are the
+// D50-adapted colorants used in the ICC sRGB profiles.
+var srgbToPCS = &matrix3{
+	0.4361, 0.3851, 0.1431,
+	0.2225, 0.7169, 0.0606,
+	0.0139, 0.0971, 0.7141,
+}
+
+var pcsToSRGB = srgbToPCS.inv()
+
+// SRGBToXYZ converts an sRGB color to PCS XYZ (relative to D50), as the
+// ICC sRGB profile does for the colorimetric intents.  The components r, g
+// and b are in the range [0, 1].
+func SRGBToXYZ(r, g, b float64) XYZ {
+	return srgbToPCS.apply([3]float64{srgbLinear(r), srgbLinear(g), srgbLinear(b)})
+}
+
+// XYZToSRGB converts a PCS XYZ color (relative to D50) to sRGB.  This is the
+// inverse of SRGBToXYZ.  Colors outside the sRGB gamut are clipped, and the
+// returned components are in the range [0, 1].
+func XYZToSRGB(c XYZ) (r, g, b float64) {
+	lin := pcsToSRGB.apply(c)
+	return srgbGamma(lin[0]), srgbGamma(lin[1]), srgbGamma(lin[2])
+}
+
+// srgbLinear applies the sRGB decoding function (IEC 61966-2-1).
+func srgbLinear(x float64) float64 {
+	if x <= 0.04045 {
+		return x / 12.92
+	}
+	return math.Pow((x+0.055)/1.055, 2.4)
+}
+
+// srgbGamma applies the sRGB encoding function, clipping the result to
+// the range [0, 1].
+func srgbGamma(x float64) float64 {
+	switch {
+	case x <= 0:
+		return 0
+	case x <= 0.0031308:
+		return 12.92 * x
+	case x >= 1:
+		return 1
+	default:
+		return 1.055*math.Pow(x, 1/2.4) - 0.055
+	}
+}
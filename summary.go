@@ -0,0 +1,116 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// Summary contains the header fields and key metadata of a profile,
+// represented as strings and numbers.  The struct is intended for use
+// in templates, JSON output and log messages.
+type Summary struct {
+	Version         string `json:"version"`
+	Class           string `json:"class"`
+	ColorSpace      string `json:"colorSpace"`
+	PCS             string `json:"pcs"`
+	RenderingIntent string `json:"renderingIntent"`
+	CreationDate    string `json:"creationDate,omitempty"`
+
+	PreferedCMMType    string `json:"preferedCMMType,omitempty"`
+	PrimaryPlatform    string `json:"primaryPlatform,omitempty"`
+	Flags              uint32 `json:"flags"`
+	DeviceManufacturer string `json:"deviceManufacturer,omitempty"`
+	DeviceModel        string `json:"deviceModel,omitempty"`
+	DeviceAttributes   uint64 `json:"deviceAttributes"`
+	Creator            string `json:"creator,omitempty"`
+	CheckSum           string `json:"checkSum"`
+
+	Description string `json:"description,omitempty"`
+	Copyright   string `json:"copyright,omitempty"`
+
+	Size int      `json:"size"`
+	Tags []string `json:"tags"`
+}
+
+// Summary returns a summary of the profile.  The description and
+// copyright are given in US English, if available.
+func (p *Profile) Summary() *Summary {
+	s := &Summary{
+		Version:            p.Version.String(),
+		Class:              p.Class.String(),
+		ColorSpace:         p.ColorSpace.String(),
+		PCS:                p.PCSName(),
+		RenderingIntent:    p.RenderingIntent.String(),
+		PreferedCMMType:    signatureString(p.PreferedCMMType),
+		PrimaryPlatform:    signatureString(p.PrimaryPlatform),
+		Flags:              p.Flags,
+		DeviceManufacturer: signatureString(p.DeviceManufacturer),
+		DeviceModel:        signatureString(p.DeviceModel),
+		DeviceAttributes:   p.DeviceAttributes,
+		Creator:            signatureString(p.Creator),
+		CheckSum:           p.CheckSum.String(),
+		Size:               p.EncodedSize(),
+	}
+	if !p.CreationDate.IsZero() {
+		s.CreationDate = p.CreationDate.UTC().Format(time.RFC3339)
+	}
+	if desc, err := p.Description(); err == nil {
+		s.Description = desc.Lookup("en", "US")
+	}
+	if cprt, err := p.Copyright(); err == nil {
+		s.Copyright = cprt.Lookup("en", "US")
+	}
+
+	tagTypes := make([]TagType, 0, len(p.TagData))
+	for tagType := range p.TagData {
+		tagTypes = append(tagTypes, tagType)
+	}
+	slices.Sort(tagTypes)
+	s.Tags = make([]string, len(tagTypes))
+	for i, tagType := range tagTypes {
+		s.Tags[i] = signatureString(uint32(tagType))
+	}
+
+	return s
+}
+
+// signatureString formats a four-byte signature.  Printable ASCII
+// signatures are returned as text, with trailing spaces removed.  Other
+// values are returned in hexadecimal.  Zero is mapped to the empty string.
+func signatureString(x uint32) string {
+	if x == 0 {
+		return ""
+	}
+	bb := []byte{
+		byte(x >> 24),
+		byte(x >> 16),
+		byte(x >> 8),
+		byte(x),
+	}
+	for _, c := range bb {
+		if c < 0x20 || c > 0x7E {
+			return fmt.Sprintf("0x%08X", x)
+		}
+	}
+	for len(bb) > 1 && bb[len(bb)-1] == ' ' {
+		bb = bb[:len(bb)-1]
+	}
+	return string(bb)
+}
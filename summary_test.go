@@ -0,0 +1,140 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSummary(t *testing.T) {
+	date := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	rgb, err := NewRGBProfile(SRGBPrimaries, WhitePoint(IlluminantD65, Observer2), SRGBCurve(), &ProfileOptions{
+		Description: "sRGB test",
+		Copyright:   "Public Domain",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rgb.CreationDate = date
+
+	gray, err := NewGrayProfile(PCSIlluminant, GammaCurve(2.2), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gray.Version = Version2_1_0
+	gray.CreationDate = date.In(time.FixedZone("CEST", 2*3600))
+	gray.SetDescription("Gray")
+	gray.SetCopyright("Public Domain")
+	gray.PreferedCMMType = 0x6C636D73    // "lcms"
+	gray.PrimaryPlatform = 0x4150504C    // "APPL"
+	gray.DeviceManufacturer = 0x00000001 // not printable
+	gray.Creator = 0x48502020            // "HP  "
+
+	missing := &Profile{
+		Version:    Version4_4_0,
+		Class:      OutputDeviceProfile,
+		ColorSpace: CMYKSpace,
+		PCS:        PCSLabSpace,
+		TagData: map[TagType][]byte{
+			ProfileDescription: []byte("desc"), // malformed, ignored
+		},
+	}
+
+	cases := []struct {
+		name string
+		p    *Profile
+		want *Summary
+	}{
+		{
+			name: "RGB",
+			p:    rgb,
+			want: &Summary{
+				Version:         "4.4.0",
+				Class:           "Display Device Profile",
+				ColorSpace:      "RGB",
+				PCS:             "PCSXYZ",
+				RenderingIntent: "Perceptual",
+				CreationDate:    "2024-06-01T12:00:00Z",
+				CheckSum:        "Missing",
+				Description:     "sRGB test",
+				Copyright:       "Public Domain",
+				Tags: []string{"bTRC", "bXYZ", "chad", "cprt", "desc",
+					"gTRC", "gXYZ", "rTRC", "rXYZ", "wtpt"},
+			},
+		},
+		{
+			name: "gray",
+			p:    gray,
+			want: &Summary{
+				Version:            "2.1.0",
+				Class:              "Display Device Profile",
+				ColorSpace:         "Gray",
+				PCS:                "PCSXYZ",
+				RenderingIntent:    "Perceptual",
+				CreationDate:       "2024-06-01T12:00:00Z",
+				PreferedCMMType:    "lcms",
+				PrimaryPlatform:    "APPL",
+				DeviceManufacturer: "0x00000001",
+				Creator:            "HP",
+				CheckSum:           "Missing",
+				Description:        "Gray",
+				Copyright:          "Public Domain",
+				Tags:               []string{"chad", "cprt", "desc", "kTRC", "wtpt"},
+			},
+		},
+		{
+			name: "missing tags",
+			p:    missing,
+			want: &Summary{
+				Version:         "4.4.0",
+				Class:           "Output Device Profile",
+				ColorSpace:      "CMYK",
+				PCS:             "PCSLab",
+				RenderingIntent: "Perceptual",
+				CheckSum:        "Missing",
+				Tags:            []string{"desc"},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			c.want.Size = len(c.p.Encode())
+			got := c.p.Summary()
+			if d := cmp.Diff(c.want, got); d != "" {
+				t.Errorf("wrong summary (-want +got):\n%s", d)
+			}
+
+			// The summary must be usable for JSON output.
+			var back Summary
+			data, err := json.Marshal(got)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal(data, &back); err != nil {
+				t.Fatal(err)
+			}
+			if d := cmp.Diff(got, &back); d != "" {
+				t.Errorf("JSON round trip failed (-want +got):\n%s", d)
+			}
+		})
+	}
+}
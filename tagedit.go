@@ -0,0 +1,103 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+// AToBTag returns the device to PCS tag used for the given rendering
+// intent.  Both colorimetric intents use the AToB1 tag.
+func AToBTag(intent RenderingIntent) TagType {
+	switch intent {
+	case Perceptual:
+		return AToB0
+	case Saturation:
+		return AToB2
+	default:
+		return AToB1
+	}
+}
+
+// BToATag returns the PCS to device tag used for the given rendering
+// intent.  Both colorimetric intents use the BToA1 tag.
+func BToATag(intent RenderingIntent) TagType {
+	switch intent {
+	case Perceptual:
+		return BToA0
+	case Saturation:
+		return BToA2
+	default:
+		return BToA1
+	}
+}
+
+// CopyTag replaces the contents of tag dst with the contents of tag src.
+// Both tags share the same data afterwards, and Encode stores the data only
+// once.  For example, p.CopyTag(BToA0, BToA1) makes the perceptual intent
+// behave like the colorimetric intent.
+func (p *Profile) CopyTag(dst, src TagType) error {
+	data, ok := p.TagData[src]
+	if !ok {
+		return errMissingTag
+	}
+	p.SetTag(dst, data)
+	return nil
+}
+
+// RenameTag moves the contents of tag oldType to tag newType.  Any previous
+// contents of newType are overwritten.
+func (p *Profile) RenameTag(oldType, newType TagType) error {
+	data, ok := p.TagData[oldType]
+	if !ok {
+		return errMissingTag
+	}
+	p.DeleteTag(oldType)
+	p.SetTag(newType, data)
+	return nil
+}
+
+// SetTag sets the data for the given tag.
+//
+// Since the profile contents change, SetTag sets Dirty and resets CheckSum,
+// because the profile ID read from the original profile no longer applies.
+// Encode always computes a fresh profile ID for version 4 profiles.
+// Modifying TagData directly bypasses this tracking.
+func (p *Profile) SetTag(tagType TagType, data []byte) {
+	if p.TagData == nil {
+		p.TagData = make(map[TagType][]byte)
+	}
+	p.TagData[tagType] = data
+	p.markDirty()
+}
+
+// DeleteTag removes the given tag from the profile.
+// Like SetTag, this sets Dirty and resets CheckSum.
+func (p *Profile) DeleteTag(tagType TagType) {
+	if _, ok := p.TagData[tagType]; !ok {
+		return
+	}
+	delete(p.TagData, tagType)
+	p.markDirty()
+}
+
+// MarkClean clears the Dirty flag.  This is typically called after the
+// encoded profile has been stored.
+func (p *Profile) MarkClean() {
+	p.Dirty = false
+}
+
+func (p *Profile) markDirty() {
+	p.Dirty = true
+	p.CheckSum = CheckSumMissing
+}
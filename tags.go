@@ -29,6 +29,64 @@ func (t TagType) String() string {
 		return "Copyright"
 	case ChromaticAdaption:
 		return "Chromatic Adaption"
+	case MediaWhitePoint:
+		return "Media White Point"
+	case RedColorant:
+		return "Red Colorant"
+	case GreenColorant:
+		return "Green Colorant"
+	case BlueColorant:
+		return "Blue Colorant"
+	case RedTRC:
+		return "Red TRC"
+	case GreenTRC:
+		return "Green TRC"
+	case BlueTRC:
+		return "Blue TRC"
+	case GrayTRC:
+		return "Gray TRC"
+	case AToB0:
+		return "AToB0"
+	case AToB1:
+		return "AToB1"
+	case AToB2:
+		return "AToB2"
+	case BToA0:
+		return "BToA0"
+	case BToA1:
+		return "BToA1"
+	case BToA2:
+		return "BToA2"
+	case ViewingConditionsTag:
+		return "Viewing Conditions"
+	case DeviceSettingsTag:
+		return "Device Settings"
+	case CRDInfoTag:
+		return "CRD Info"
+	case NamedColorTag:
+		return "Named Color"
+	case ScreeningDescTag:
+		return "Screening Description"
+	case UCRBGTag:
+		return "UCR and BG"
+	case MetadataTag:
+		return "Metadata"
+	case ColorimetricIntentImageState:
+		return "Colorimetric Intent Image State"
+	case CodingIndependentCodePoints:
+		return "Coding-independent Code Points"
+	case PerceptualRenderingIntentGamut:
+		return "Perceptual Rendering Intent Gamut"
+	case SaturationRenderingIntentGamut:
+		return "Saturation Rendering Intent Gamut"
+	case WCSProfilesTag:
+		return "WCS Profiles"
+	case MakeAndModelTag:
+		return "Make and Model"
+	case NativeDisplayInfoTag:
+		return "Native Display Information"
+	case VideoCardGammaTag:
+		return "Video Card Gamma"
 	default:
 		bb := []byte{
 			byte(t >> 24),
@@ -55,6 +113,40 @@ const (
 	ProfileDescription TagType = 0x64657363 // "desc"
 	Copyright          TagType = 0x63707274 // "cprt"
 	ChromaticAdaption  TagType = 0x63686164 // "chad"
+	MediaWhitePoint    TagType = 0x77747074 // "wtpt"
+	RedColorant        TagType = 0x7258595A // "rXYZ"
+	GreenColorant      TagType = 0x6758595A // "gXYZ"
+	BlueColorant       TagType = 0x6258595A // "bXYZ"
+	RedTRC             TagType = 0x72545243 // "rTRC"
+	GreenTRC           TagType = 0x67545243 // "gTRC"
+	BlueTRC            TagType = 0x62545243 // "bTRC"
+	GrayTRC            TagType = 0x6B545243 // "kTRC"
+	AToB0              TagType = 0x41324230 // "A2B0"
+	AToB1              TagType = 0x41324231 // "A2B1"
+	AToB2              TagType = 0x41324232 // "A2B2"
+	BToA0              TagType = 0x42324130 // "B2A0"
+	BToA1              TagType = 0x42324131 // "B2A1"
+	BToA2              TagType = 0x42324132 // "B2A2"
+
+	ViewingConditionsTag TagType = 0x76696577 // "view"
+	DeviceSettingsTag    TagType = 0x64657673 // "devs", version 2 only
+	CRDInfoTag           TagType = 0x63726469 // "crdi", version 2 only
+	NamedColorTag        TagType = 0x6E636F6C // "ncol", version 2 only
+	ScreeningDescTag     TagType = 0x73637264 // "scrd", version 2 only
+	UCRBGTag             TagType = 0x62666420 // "bfd ", version 2 only
+	MetadataTag          TagType = 0x6D657461 // "meta"
+
+	ColorimetricIntentImageState TagType = 0x63696973 // "ciis"
+	CodingIndependentCodePoints  TagType = 0x63696370 // "cicp"
+
+	PerceptualRenderingIntentGamut TagType = 0x72696730 // "rig0"
+	SaturationRenderingIntentGamut TagType = 0x72696732 // "rig2"
+
+	WCSProfilesTag TagType = 0x4D533030 // "MS00", private tag used by Windows
+
+	MakeAndModelTag      TagType = 0x6D6D6F64 // "mmod", private tag used by Apple
+	NativeDisplayInfoTag TagType = 0x6E64696E // "ndin", private tag used by Apple
+	VideoCardGammaTag    TagType = 0x76636774 // "vcgt", private tag used by Apple
 )
 
 // Copyright returns the contents of the copyright tag.
@@ -63,7 +155,7 @@ func (p *Profile) Copyright() (MultiLocalizedUnicode, error) {
 	if !ok {
 		return nil, errMissingTag
 	}
-	val, err := decodeMLUC(tag)
+	val, err := decodeMLUC(tag, false)
 	if err != errUnexpectedType {
 		return val, err
 	}
@@ -81,3 +173,119 @@ func (p *Profile) Copyright() (MultiLocalizedUnicode, error) {
 	}
 	return val, nil
 }
+
+// SetCopyright sets the copyright tag.  For version 4 profiles, the
+// text is stored as a multiLocalizedUnicodeType element with language
+// "en" and country "US".  For older profiles, a textType element is used
+// and non-ASCII characters are replaced by '?'.
+func (p *Profile) SetCopyright(s string) {
+	// This cannot fail, since the language and country codes are valid.
+	_ = p.SetLocalizedCopyright(MultiLocalizedUnicode{
+		{Language: "en", Country: "US", Value: s},
+	})
+}
+
+// SetLocalizedCopyright sets the copyright tag to a text in several
+// languages.  For version 4 profiles, a multiLocalizedUnicodeType element
+// is used.  Older profiles can only store a single ASCII string, so the
+// US English text is used, as selected by m.Lookup("en", "US").
+// An error is returned if m contains invalid language or country codes.
+func (p *Profile) SetLocalizedCopyright(m MultiLocalizedUnicode) error {
+	data, err := m.EncodeText(p.Version)
+	if err != nil {
+		return err
+	}
+	p.SetTag(Copyright, data)
+	return nil
+}
+
+// Description returns the contents of the profile description tag.
+func (p *Profile) Description() (MultiLocalizedUnicode, error) {
+	tag, ok := p.TagData[ProfileDescription]
+	if !ok {
+		return nil, errMissingTag
+	}
+	val, err := decodeMLUC(tag, false)
+	if err != errUnexpectedType {
+		return val, err
+	}
+
+	s, err := decodeTextDescription(tag)
+	if err != nil {
+		return nil, err
+	}
+	val = MultiLocalizedUnicode{
+		{
+			Language: "en",
+			Country:  "US",
+			Value:    s,
+		},
+	}
+	return val, nil
+}
+
+// SetDescription sets the profile description tag.  For version 4
+// profiles, the text is stored as a multiLocalizedUnicodeType element with
+// language "en" and country "US".  For older profiles, a
+// textDescriptionType element is used and non-ASCII characters are
+// replaced by '?'.
+func (p *Profile) SetDescription(s string) {
+	// This cannot fail, since the language and country codes are valid.
+	_ = p.SetLocalizedDescription(MultiLocalizedUnicode{
+		{Language: "en", Country: "US", Value: s},
+	})
+}
+
+// SetLocalizedDescription sets the profile description tag to a text in
+// several languages.  For version 4 profiles, a multiLocalizedUnicodeType
+// element is used.  For older profiles, the US English text is stored in
+// a textDescriptionType element, as for SetDescription.
+// An error is returned if m contains invalid language or country codes.
+func (p *Profile) SetLocalizedDescription(m MultiLocalizedUnicode) error {
+	data, err := m.EncodeDescription(p.Version)
+	if err != nil {
+		return err
+	}
+	p.SetTag(ProfileDescription, data)
+	return nil
+}
+
+// WhitePoint returns the contents of the media white point tag, relative
+// to the PCS illuminant.
+func (p *Profile) WhitePoint() (XYZ, error) {
+	tag, ok := p.TagData[MediaWhitePoint]
+	if !ok {
+		return XYZ{}, errMissingTag
+	}
+	return decodeXYZ(tag)
+}
+
+// SetWhitePoint sets the media white point tag.  The same XYZType element
+// is used for all profile versions.  For version 4 display profiles, the
+// specification requires the white point to equal the PCS illuminant;
+// the adaptation from the actual display white is then recorded in the
+// chromatic adaptation tag.
+func (p *Profile) SetWhitePoint(c XYZ) {
+	p.SetTag(MediaWhitePoint, encodeXYZ(c))
+}
+
+// isV4 returns true if the profile will be encoded as a version 4 profile.
+func (p *Profile) isV4() bool {
+	return p.Version == 0 || p.Version >= Version4_0_0
+}
+
+// TagElementType returns the type signature of the tag element stored in
+// data, for example "mluc" or "desc".  If data is too short to contain a
+// type signature, the function returns false.
+func TagElementType(data []byte) (string, bool) {
+	if len(data) < 4 {
+		return "", false
+	}
+	return string(data[:4]), true
+}
+
+// TagElementType returns the type signature of the given tag.  If the tag
+// is missing or too short, the function returns false.
+func (p *Profile) TagElementType(tagType TagType) (string, bool) {
+	return TagElementType(p.TagData[tagType])
+}
@@ -0,0 +1,440 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWCSProfilesRoundTrip(t *testing.T) {
+	// "<a/>" in UTF-16LE, with byte order mark
+	cdm := []byte{0xFF, 0xFE, '<', 0, 'a', 0, '/', 0, '>', 0}
+	in := &WCSProfiles{
+		ColorDeviceModel: cdm,
+		GamutMapModel:    []byte("<b/>"),
+	}
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetWCSProfiles(in)
+
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := q.WCSProfiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(in, out); d != "" {
+		t.Fatalf("round trip failed (-want +got):\n%s", d)
+	}
+	if s := out.ColorDeviceModelXML(); s != "<a/>" {
+		t.Errorf("got CDM %q, want %q", s, "<a/>")
+	}
+	if s := out.GamutMapModelXML(); s != "<b/>" {
+		t.Errorf("got GMM %q, want %q", s, "<b/>")
+	}
+}
+
+func TestVideoCardGammaRoundTrip(t *testing.T) {
+	cases := []*VideoCardGamma{
+		{
+			Table:     [][]uint16{{0, 0x8000, 0xFFFF}, {0, 1, 2}, {7, 8, 9}},
+			EntrySize: 2,
+		},
+		{
+			Table:     [][]uint16{{0, 128, 255}},
+			EntrySize: 1,
+		},
+		{
+			Formula: [3]VideoCardGammaFormula{
+				{Gamma: 1, Min: 0, Max: 1},
+				{Gamma: 2.2, Min: 0.25, Max: 0.5},
+				{Gamma: 1.8, Min: 0, Max: 0.75},
+			},
+		},
+	}
+	for i, in := range cases {
+		data, err := in.encode()
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		out, err := decodeVideoCardGamma(data)
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if d := cmp.Diff(in, out, cmp.Comparer(approxEqual)); d != "" {
+			t.Errorf("%d: round trip failed (-want +got):\n%s", i, d)
+		}
+	}
+}
+
+func TestMakeAndModelRoundTrip(t *testing.T) {
+	in := &MakeAndModel{
+		Manufacturer:    0x4150504C, // "APPL"
+		Model:           0xA050,
+		SerialNumber:    12345678,
+		ManufactureDate: 0x07E8_0014,
+	}
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetMakeAndModel(in)
+	out, err := p.MakeAndModel()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *out != *in {
+		t.Errorf("round trip failed: got %v, want %v", out, in)
+	}
+
+	if _, err := decodeMakeAndModel([]byte("mmod\x00\x00\x00\x00")); err == nil {
+		t.Error("short tag not detected")
+	}
+}
+
+func TestNativeDisplayInfoRoundTrip(t *testing.T) {
+	cases := []*NativeDisplayInfo{
+		{
+			Red:   [2]float64{0.64, 0.33},
+			Green: [2]float64{0.3, 0.6},
+			Blue:  [2]float64{0.15, 0.06},
+			White: [2]float64{0.3127, 0.329},
+			Gamma: [3]float64{2.2, 2.2, 2.4},
+		},
+		{
+			Gamma:          [3]float64{1.8, 1.8, 1.8},
+			GammaTable:     [][]uint16{{0, 0x8000, 0xFFFF}, {0, 1, 2}, {7, 8, 9}},
+			GammaEntrySize: 2,
+		},
+		{
+			GammaTable:     [][]uint16{{0, 255}},
+			GammaEntrySize: 1,
+		},
+	}
+	for i, in := range cases {
+		p := &Profile{TagData: make(map[TagType][]byte)}
+		if err := p.SetNativeDisplayInfo(in); err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		out, err := p.NativeDisplayInfo()
+		if err != nil {
+			t.Fatalf("%d: %v", i, err)
+		}
+		if d := cmp.Diff(in, out, cmp.Comparer(approxEqual)); d != "" {
+			t.Errorf("%d: round trip failed (-want +got):\n%s", i, d)
+		}
+	}
+}
+
+func TestGammaTableErrors(t *testing.T) {
+	cases := []*VideoCardGamma{
+		{Table: [][]uint16{{0, 1, 2}, {0, 1}}, EntrySize: 2},  // lengths differ
+		{Table: [][]uint16{{0, 256}}, EntrySize: 1},           // value too large
+		{Table: [][]uint16{{0, 1}}, EntrySize: 3},             // invalid entry size
+		{Table: [][]uint16{{}, {}, {}}, EntrySize: 2},         // no entries
+		{Table: [][]uint16{{0}, {0}, {0}, {0}}, EntrySize: 2}, // too many channels
+	}
+	for i, v := range cases {
+		p := &Profile{TagData: make(map[TagType][]byte)}
+		if err := p.SetVideoCardGamma(v); err == nil {
+			t.Errorf("%d: invalid table not detected", i)
+		}
+		if _, ok := p.TagData[VideoCardGammaTag]; ok {
+			t.Errorf("%d: tag set despite error", i)
+		}
+	}
+
+	n := &NativeDisplayInfo{GammaTable: [][]uint16{{0}, {}}, GammaEntrySize: 2}
+	if _, err := n.encode(); err == nil {
+		t.Error("ndin: invalid table not detected")
+	}
+
+	for _, data := range [][]byte{
+		// 65535 channels with no entries
+		{'v', 'c', 'g', 't', 0, 0, 0, 0, 0, 0, 0, 0, 0xFF, 0xFF, 0, 0, 0, 2},
+		// entries extend beyond the end of the tag
+		{'v', 'c', 'g', 't', 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, 0xFF, 0xFF, 0, 2, 0, 0},
+	} {
+		if _, err := decodeVideoCardGamma(data); err == nil {
+			t.Errorf("malformed table %x not detected", data)
+		}
+	}
+}
+
+func TestCICPRoundTrip(t *testing.T) {
+	for _, in := range []*CICP{
+		{ColorPrimaries: 9, TransferCharacteristics: 16, MatrixCoefficients: 0, VideoFullRange: true},
+		{ColorPrimaries: 1, TransferCharacteristics: 13},
+	} {
+		p := &Profile{TagData: make(map[TagType][]byte)}
+		p.SetCICP(in)
+		out, err := p.CICP()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if *out != *in {
+			t.Errorf("round trip failed: got %v, want %v", out, in)
+		}
+	}
+
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	if _, err := p.CICP(); err != errMissingTag {
+		t.Errorf("missing tag: got %v", err)
+	}
+	p.TagData[CodingIndependentCodePoints] = []byte("cicp\x00\x00\x00\x00\x09\x10\x00")
+	if _, err := p.CICP(); err != errInvalidTagData {
+		t.Errorf("short tag: got %v", err)
+	}
+	p.TagData[CodingIndependentCodePoints] = []byte("cicp\x00\x00\x00\x00\x09\x10\x00\x02")
+	if _, err := p.CICP(); err != errInvalidTagData {
+		t.Errorf("invalid range flag: got %v", err)
+	}
+}
+
+func TestImageStateRoundTrip(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	if !p.UseMediaWhiteForAbsolute() {
+		t.Error("missing tag should use media white")
+	}
+	for _, in := range []ImageState{SceneColorimetryEstimates, ReflectionPrintOutputColorimetry} {
+		p.SetColorimetricIntentImageState(in)
+		out, err := p.ColorimetricIntentImageState()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if out != in {
+			t.Errorf("round trip failed: got %s, want %s", out, in)
+		}
+		if p.UseMediaWhiteForAbsolute() == in.IsSceneReferred() {
+			t.Errorf("%s: wrong media white handling", in)
+		}
+	}
+
+	p.TagData[ColorimetricIntentImageState] = []byte("sig \x00\x00\x00\x00sco")
+	if _, err := p.ColorimetricIntentImageState(); err != errInvalidTagData {
+		t.Errorf("short tag: got %v", err)
+	}
+	p.TagData[ColorimetricIntentImageState] = []byte("text\x00\x00\x00\x00scoe")
+	if _, err := p.ColorimetricIntentImageState(); err != errUnexpectedType {
+		t.Errorf("wrong type: got %v", err)
+	}
+}
+
+func approxEqual(a, b float64) bool {
+	d := a - b
+	return d > -1.0/65536 && d < 1.0/65536
+}
+
+func TestSetDescription(t *testing.T) {
+	for _, version := range []Version{Version2_1_0, Version4_4_0} {
+		p := &Profile{Version: version, TagData: make(map[TagType][]byte)}
+		p.SetDescription("Test Profile")
+		p.SetCopyright("Public Domain")
+
+		desc, err := p.Description()
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		if len(desc) != 1 || desc[0].Value != "Test Profile" {
+			t.Errorf("%s: wrong description %v", version, desc)
+		}
+		cprt, err := p.Copyright()
+		if err != nil {
+			t.Fatalf("%s: %v", version, err)
+		}
+		if len(cprt) != 1 || cprt[0].Value != "Public Domain" {
+			t.Errorf("%s: wrong copyright %v", version, cprt)
+		}
+	}
+}
+
+func TestSetLocalizedCopyright(t *testing.T) {
+	m := MultiLocalizedUnicode{
+		{Language: "de", Country: "DE", Value: "Gemeinfrei"},
+		{Language: "en", Country: "US", Value: "Public Domain"},
+	}
+
+	p := &Profile{Version: Version4_4_0, TagData: make(map[TagType][]byte)}
+	if err := p.SetLocalizedCopyright(m); err != nil {
+		t.Fatal(err)
+	}
+	cprt, err := p.Copyright()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(m, cprt); d != "" {
+		t.Errorf("wrong copyright (-want +got):\n%s", d)
+	}
+
+	p = &Profile{Version: Version2_1_0, TagData: make(map[TagType][]byte)}
+	if err := p.SetLocalizedCopyright(m); err != nil {
+		t.Fatal(err)
+	}
+	cprt, err = p.Copyright()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cprt.Lookup("en", "US"); got != "Public Domain" {
+		t.Errorf("wrong copyright %q", got)
+	}
+
+	bad := MultiLocalizedUnicode{{Language: "deu", Country: "DE", Value: "Gemeinfrei"}}
+	if err := p.SetLocalizedCopyright(bad); err == nil {
+		t.Error("invalid language code not detected")
+	}
+}
+
+func TestWhitePoint(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetWhitePoint(PCSIlluminant)
+	if elemType, _ := p.TagElementType(MediaWhitePoint); elemType != "XYZ " {
+		t.Errorf("wrong element type %q", elemType)
+	}
+	white, err := p.WhitePoint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range white {
+		if !approxEqual(white[i], PCSIlluminant[i]) {
+			t.Errorf("wrong white point %v", white)
+			break
+		}
+	}
+}
+
+func TestCopyTag(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte), CheckSum: CheckSumValid}
+	p.TagData[BToA1] = []byte("mft2 some LUT data")
+	sizeBefore := p.EncodedSize()
+
+	err := p.CopyTag(BToATag(Perceptual), BToATag(RelativeColorimetric))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(p.TagData[BToA0]) != "mft2 some LUT data" {
+		t.Errorf("tag not copied")
+	}
+	if p.CheckSum != CheckSumMissing || !p.Dirty {
+		t.Errorf("modification not tracked")
+	}
+	_ = p.Encode()
+	if !p.Dirty {
+		t.Errorf("Dirty cleared by Encode")
+	}
+	p.MarkClean()
+	if p.Dirty {
+		t.Errorf("Dirty not cleared by MarkClean")
+	}
+	if size := p.EncodedSize(); size != sizeBefore+12 {
+		t.Errorf("shared data not deduplicated: size %d, want %d", size, sizeBefore+12)
+	}
+
+	err = p.RenameTag(BToA0, BToA2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := p.TagData[BToA0]; ok {
+		t.Errorf("old tag still present")
+	}
+	if err := p.CopyTag(AToB0, BToA0); err == nil {
+		t.Errorf("missing source tag not detected")
+	}
+}
+
+func TestMetadata(t *testing.T) {
+	in := Dict{
+		{Name: "ManufacturerName", Value: "ACME"},
+		{Name: "empty"},
+		{
+			Name:         "MediaWeight",
+			Value:        "150",
+			DisplayName:  MultiLocalizedUnicode{{Language: "de", Country: "DE", Value: "Grammatur"}},
+			DisplayValue: MultiLocalizedUnicode{{Language: "de", Country: "DE", Value: "150 g/m²"}},
+		},
+	}
+	data, err := in.encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := decodeDict(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(in, out); d != "" {
+		t.Errorf("round trip failed (-want +got):\n%s", d)
+	}
+}
+
+func TestDictSet(t *testing.T) {
+	orig := make(Dict, 1, 2) // spare capacity must not be used
+	orig[0] = &DictEntry{Name: "a", Value: "1"}
+
+	d := orig.Set("a", "2")
+	if orig[0].Value != "1" {
+		t.Error("original entry modified")
+	}
+	if val, _ := d.Lookup("a"); val != "2" {
+		t.Errorf("wrong value %q", val)
+	}
+
+	d = orig.Set("b", "3")
+	if len(d) != 2 || orig[:2][1] != nil {
+		t.Error("original slice modified")
+	}
+}
+
+func TestFingerprint(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	measurements := []byte("CGATS.17\n...")
+
+	if _, err := p.VerifyFingerprint("SourceHash", measurements); err == nil {
+		t.Error("missing metadata not detected")
+	}
+
+	if err := p.SetMetadataValue("Generator", "example 1.0"); err != nil {
+		t.Fatal(err)
+	}
+	for range 2 { // the second call replaces the first entry
+		if err := p.EmbedFingerprint("SourceHash", measurements); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	ok, err := q.VerifyFingerprint("SourceHash", measurements)
+	if err != nil || !ok {
+		t.Errorf("fingerprint not verified: %t %v", ok, err)
+	}
+	ok, err = q.VerifyFingerprint("SourceHash", []byte("other data"))
+	if err != nil || ok {
+		t.Errorf("wrong data accepted: %t %v", ok, err)
+	}
+
+	d, err := q.Metadata()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(d) != 2 {
+		t.Errorf("wrong number of entries: %d", len(d))
+	}
+	if val, _ := d.Lookup("Generator"); val != "example 1.0" {
+		t.Errorf("wrong generator %q", val)
+	}
+}
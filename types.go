@@ -38,6 +38,65 @@ func decodeText(data []byte) (string, error) {
 	return string(data[start:end]), nil
 }
 
+func encodeText(s string) []byte {
+	buf := make([]byte, 8, 8+len(s)+1)
+	copy(buf, "text")
+	buf = append(buf, toASCII(s)...)
+	buf = append(buf, 0)
+	return buf
+}
+
+// decodeTextDescription decodes a textDescriptionType element, as used for
+// the description tag in version 2 profiles.  Only the ASCII part of the
+// element is used.
+func decodeTextDescription(data []byte) (string, error) {
+	err := checkType("desc", data)
+	if err != nil {
+		return "", err
+	}
+
+	if len(data) < 12 {
+		return "", errInvalidTagData
+	}
+	n := getUint32(data, 8)
+	if uint64(n) > uint64(len(data)-12) {
+		return "", errInvalidTagData
+	}
+	start := 12
+	end := 12 + int(n)
+	for end > start && data[end-1] == 0 {
+		end--
+	}
+	return string(data[start:end]), nil
+}
+
+// encodeTextDescription encodes s as a textDescriptionType element, as used
+// for the description tag in version 2 profiles.  Only the ASCII part of
+// the element is filled in.
+func encodeTextDescription(s string) []byte {
+	ascii := toASCII(s)
+	buf := make([]byte, 12, 12+len(ascii)+1+8+3+67)
+	copy(buf, "desc")
+	putUint32(buf, 8, uint32(len(ascii)+1))
+	buf = append(buf, ascii...)
+	buf = append(buf, 0)
+	buf = append(buf, make([]byte, 8+3+67)...)
+	return buf
+}
+
+// toASCII replaces all non-ASCII characters in s with '?'.
+func toASCII(s string) []byte {
+	res := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7F {
+			res = append(res, byte(r))
+		} else {
+			res = append(res, '?')
+		}
+	}
+	return res
+}
+
 // MultiLocalizedUnicode represents a localized Unicode string.
 type MultiLocalizedUnicode []LocalizedUnicode
 
@@ -48,7 +107,35 @@ type LocalizedUnicode struct {
 	Value    string
 }
 
-func decodeMLUC(data []byte) (MultiLocalizedUnicode, error) {
+// Lookup returns the string best matching the given language and country
+// codes.  If an exact match is not found, the first record with the given
+// language is used.  If no record has the given language, the first record
+// is used.  If m is empty, the empty string is returned.
+func (m MultiLocalizedUnicode) Lookup(language, country string) string {
+	if len(m) == 0 {
+		return ""
+	}
+	for _, lu := range m {
+		if lu.Language == language && lu.Country == country {
+			return lu.Value
+		}
+	}
+	for _, lu := range m {
+		if lu.Language == language {
+			return lu.Value
+		}
+	}
+	return m[0].Value
+}
+
+// decodeMLUC decodes a multiLocalizedUnicodeType element.  A country code
+// consisting of two zero bytes is returned as the empty string.
+//
+// If lenient is true, malformed strings are repaired instead of causing an
+// error: odd-length records are truncated, records which extend beyond the
+// end of the data are clamped, and unpaired surrogates are replaced by
+// U+FFFD.
+func decodeMLUC(data []byte, lenient bool) (MultiLocalizedUnicode, error) {
 	err := checkType("mluc", data)
 	if err != nil {
 		return nil, err
@@ -59,35 +146,240 @@ func decodeMLUC(data []byte) (MultiLocalizedUnicode, error) {
 	}
 	n := getUint32(data, 8)
 
-	if n == 0 || uint64(len(data)) < 16+12*uint64(n) {
+	if uint64(len(data)) < 16+12*uint64(n) {
 		return nil, errInvalidTagData
 	}
 	res := make(MultiLocalizedUnicode, n)
 	for i := range res {
 		language := string(data[16+12*i : 16+12*i+2])
 		country := string(data[16+12*i+2 : 16+12*i+4])
+		if country == "\000\000" {
+			country = ""
+		}
 		length := getUint32(data, 16+12*i+4)
 		offset := getUint32(data, 16+12*i+8)
 
 		start := uint64(offset)
 		end := start + uint64(length)
-		if end > uint64(len(data)) || length&1 != 0 {
-			return nil, errInvalidTagData
+		if end > uint64(len(data)) {
+			if !lenient || start > uint64(len(data)) {
+				return nil, errInvalidTagData
+			}
+			end = uint64(len(data))
 		}
 
-		d16 := make([]uint16, length/2)
-		for j := range d16 {
-			d16[j] = uint16(data[start+2*uint64(j)])<<8 | uint16(data[start+2*uint64(j)+1])
+		value, err := decodeUTF16BE(data[start:end], lenient)
+		if err != nil {
+			return nil, err
 		}
 		res[i] = LocalizedUnicode{
 			Language: language,
 			Country:  country,
-			Value:    string(utf16.Decode(d16)),
+			Value:    value,
 		}
 	}
 	return res, nil
 }
 
+// decodeUTF16BE decodes a UTF-16 string, stored in big-endian byte order.
+// A leading byte order mark is removed, and switches the decoder to
+// little-endian byte order if required.
+//
+// If lenient is false, an error is returned for odd-length data and for
+// unpaired surrogates.  Otherwise, a trailing odd byte is ignored and
+// unpaired surrogates are replaced by U+FFFD.
+func decodeUTF16BE(buf []byte, lenient bool) (string, error) {
+	if len(buf)%2 != 0 {
+		if !lenient {
+			return "", errInvalidTagData
+		}
+		buf = buf[:len(buf)-1]
+	}
+
+	littleEndian := false
+	if len(buf) >= 2 {
+		if buf[0] == 0xFE && buf[1] == 0xFF {
+			buf = buf[2:]
+		} else if buf[0] == 0xFF && buf[1] == 0xFE {
+			buf = buf[2:]
+			littleEndian = true
+		}
+	}
+
+	d16 := make([]uint16, len(buf)/2)
+	for i := range d16 {
+		if littleEndian {
+			d16[i] = uint16(buf[2*i]) | uint16(buf[2*i+1])<<8
+		} else {
+			d16[i] = uint16(buf[2*i])<<8 | uint16(buf[2*i+1])
+		}
+	}
+
+	if !lenient {
+		for i := 0; i < len(d16); i++ {
+			switch {
+			case d16[i] >= 0xD800 && d16[i] < 0xDC00:
+				if i+1 >= len(d16) || d16[i+1] < 0xDC00 || d16[i+1] >= 0xE000 {
+					return "", errInvalidTagData
+				}
+				i++
+			case d16[i] >= 0xDC00 && d16[i] < 0xE000:
+				return "", errInvalidTagData
+			}
+		}
+	}
+
+	// utf16.Decode replaces unpaired surrogates with U+FFFD.
+	return string(utf16.Decode(d16)), nil
+}
+
+// Encode encodes m as a multiLocalizedUnicodeType element.  Records with
+// identical values share the same string storage.
+//
+// An error is returned if a language code is not a two-letter ASCII code,
+// or if a country code is neither empty nor a two-letter ASCII code.  An
+// empty country code is stored as two zero bytes.
+func (m MultiLocalizedUnicode) Encode() ([]byte, error) {
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+
+	pos := 16 + 12*len(m)
+	var strings []byte
+	offsets := make(map[string]int)
+	buf := make([]byte, pos)
+	copy(buf, "mluc")
+	putUint32(buf, 8, uint32(len(m)))
+	putUint32(buf, 12, 12)
+	for i, lu := range m {
+		d := encodeUTF16BE(lu.Value)
+		offset, seen := offsets[lu.Value]
+		if !seen {
+			offset = pos + len(strings)
+			offsets[lu.Value] = offset
+			strings = append(strings, d...)
+		}
+		copy(buf[16+12*i:16+12*i+2], lu.Language)
+		copy(buf[16+12*i+2:16+12*i+4], lu.Country)
+		putUint32(buf, 16+12*i+4, uint32(len(d)))
+		putUint32(buf, 16+12*i+8, uint32(offset))
+	}
+	return append(buf, strings...), nil
+}
+
+// check verifies that all language and country codes can be stored in a
+// multiLocalizedUnicodeType element.
+func (m MultiLocalizedUnicode) check() error {
+	for _, lu := range m {
+		if !isLetterCode(lu.Language) || lu.Country != "" && !isLetterCode(lu.Country) {
+			return errInvalidLanguage
+		}
+	}
+	return nil
+}
+
+// isLetterCode reports whether s consists of exactly two ASCII letters.
+func isLetterCode(s string) bool {
+	if len(s) != 2 {
+		return false
+	}
+	for i := 0; i < 2; i++ {
+		c := s[i] | 0x20 // convert to lower case
+		if c < 'a' || c > 'z' {
+			return false
+		}
+	}
+	return true
+}
+
+// EncodeDescription encodes m in the form used for the profile description
+// tag in the given profile version.  For version 4 profiles, this is the
+// same as Encode.  For older profiles, the US English text, as selected
+// by m.Lookup("en", "US"), is stored as a textDescriptionType element and
+// non-ASCII characters are replaced by '?'.  A version of 0 is treated as
+// the current version.  The language and country codes are checked as for
+// Encode, even if they are not stored.
+func (m MultiLocalizedUnicode) EncodeDescription(v Version) ([]byte, error) {
+	if v == 0 || v >= Version4_0_0 {
+		return m.Encode()
+	}
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	return encodeTextDescription(m.Lookup("en", "US")), nil
+}
+
+// EncodeText encodes m in the form used for text tags, like the copyright
+// tag, in the given profile version.  For version 4 profiles, this is the
+// same as Encode.  For older profiles, the US English text is stored as a
+// textType element and non-ASCII characters are replaced by '?'.  A
+// version of 0 is treated as the current version.  The language and country
+// codes are checked as for Encode.
+func (m MultiLocalizedUnicode) EncodeText(v Version) ([]byte, error) {
+	if v == 0 || v >= Version4_0_0 {
+		return m.Encode()
+	}
+	if err := m.check(); err != nil {
+		return nil, err
+	}
+	return encodeText(m.Lookup("en", "US")), nil
+}
+
+// encodeUTF16BE encodes s as UTF-16BE.  Normally no byte order mark is
+// written.  If s starts with U+FEFF or U+FFFE, a byte order mark is
+// prepended, so that decodeUTF16BE does not mistake the first character
+// for a byte order mark.  This is the inverse of decodeUTF16BE.
+func encodeUTF16BE(s string) []byte {
+	d16 := utf16.Encode([]rune(s))
+	if len(d16) > 0 && (d16[0] == 0xFEFF || d16[0] == 0xFFFE) {
+		// protect the leading character from being read as a BOM
+		d16 = append([]uint16{0xFEFF}, d16...)
+	}
+	buf := make([]byte, 2*len(d16))
+	for i, c := range d16 {
+		buf[2*i] = byte(c >> 8)
+		buf[2*i+1] = byte(c)
+	}
+	return buf
+}
+
+func decodeXYZ(data []byte) (XYZ, error) {
+	err := checkType("XYZ ", data)
+	if err != nil {
+		return XYZ{}, err
+	}
+	if len(data) < 20 {
+		return XYZ{}, errInvalidTagData
+	}
+	return getXYZ(data, 8), nil
+}
+
+func encodeXYZ(c XYZ) []byte {
+	buf := make([]byte, 20)
+	copy(buf, "XYZ ")
+	putXYZ(buf, 8, c)
+	return buf
+}
+
+func decodeSignature(data []byte) (uint32, error) {
+	err := checkType("sig ", data)
+	if err != nil {
+		return 0, err
+	}
+
+	if len(data) < 12 {
+		return 0, errInvalidTagData
+	}
+	return getUint32(data, 8), nil
+}
+
+func encodeSignature(sig uint32) []byte {
+	buf := make([]byte, 12)
+	copy(buf, "sig ")
+	putUint32(buf, 8, sig)
+	return buf
+}
+
 func checkType(typeID string, data []byte) error {
 	bb := []byte(typeID)
 	for i, b := range bb {
@@ -99,7 +391,8 @@ func checkType(typeID string, data []byte) error {
 }
 
 var (
-	errMissingTag     = errors.New("missing tag")
-	errUnexpectedType = errors.New("unexpected tag data type")
-	errInvalidTagData = errors.New("invalid tag data")
+	errMissingTag      = errors.New("missing tag")
+	errUnexpectedType  = errors.New("unexpected tag data type")
+	errInvalidTagData  = errors.New("invalid tag data")
+	errInvalidLanguage = errors.New("invalid language or country code")
 )
@@ -0,0 +1,198 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"golang.org/x/text/language"
+)
+
+func TestLookup(t *testing.T) {
+	m := MultiLocalizedUnicode{
+		{Language: "en", Country: "US", Value: "color"},
+		{Language: "de", Country: "DE", Value: "Farbe"},
+		{Language: "en", Country: "GB", Value: "colour"},
+	}
+	cases := []struct {
+		language, country, want string
+	}{
+		{"en", "GB", "colour"},
+		{"en", "US", "color"},
+		{"en", "AU", "color"},
+		{"de", "AT", "Farbe"},
+		{"fr", "FR", "color"},
+	}
+	for _, c := range cases {
+		got := m.Lookup(c.language, c.country)
+		if got != c.want {
+			t.Errorf("Lookup(%q, %q) = %q, want %q", c.language, c.country, got, c.want)
+		}
+	}
+
+	if got := MultiLocalizedUnicode(nil).Lookup("en", "US"); got != "" {
+		t.Errorf("empty list: got %q", got)
+	}
+}
+
+func TestLanguageTag(t *testing.T) {
+	lu := LocalizedUnicode{Language: "de", Country: "AT"}
+	tag := lu.LanguageTag()
+	if tag != language.MustParse("de-AT") {
+		t.Errorf("got %s, want de-AT", tag)
+	}
+
+	lu2, err := NewLocalizedUnicode(tag, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lu2 != lu {
+		t.Errorf("got %v, want %v", lu2, lu)
+	}
+
+	lu3, err := NewLocalizedUnicode(language.English, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if lu3.Language != "en" || lu3.Country != "US" {
+		t.Errorf("got %s_%s, want en_US", lu3.Language, lu3.Country)
+	}
+}
+
+func TestDecodeUTF16BE(t *testing.T) {
+	cases := []struct {
+		in      []byte
+		strict  string
+		lenient string
+		fail    bool
+	}{
+		{in: []byte{0, 'a', 0, 'b'}, strict: "ab", lenient: "ab"},
+		{in: []byte{0xFE, 0xFF, 0, 'a'}, strict: "a", lenient: "a"},
+		{in: []byte{0xFF, 0xFE, 'a', 0}, strict: "a", lenient: "a"},
+		{in: []byte{0xD8, 0x3D, 0xDE, 0x00}, strict: "\U0001F600", lenient: "\U0001F600"},
+		{in: []byte{0, 'a', 0}, lenient: "a", fail: true},
+		{in: []byte{0xD8, 0x3D, 0, 'a'}, lenient: "�a", fail: true},
+		{in: []byte{0xDE, 0x00}, lenient: "�", fail: true},
+	}
+	for i, c := range cases {
+		got, err := decodeUTF16BE(c.in, false)
+		if c.fail {
+			if err == nil {
+				t.Errorf("%d: invalid data not detected", i)
+			}
+		} else if err != nil || got != c.strict {
+			t.Errorf("%d: strict: got %q, %v, want %q", i, got, err, c.strict)
+		}
+
+		got, err = decodeUTF16BE(c.in, true)
+		if err != nil || got != c.lenient {
+			t.Errorf("%d: lenient: got %q, %v, want %q", i, got, err, c.lenient)
+		}
+	}
+}
+
+func TestEncodeMLUC(t *testing.T) {
+	m := MultiLocalizedUnicode{
+		{Language: "en", Country: "US", Value: "colour"},
+		{Language: "de", Country: "DE", Value: "Farbe"},
+		{Language: "en", Country: "GB", Value: "colour"},
+	}
+	data, err := m.Encode()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := 16 + 3*12 + 2*len("colourFarbe"); len(data) != want {
+		t.Errorf("wrong length %d, want %d", len(data), want)
+	}
+	if getUint32(data, 16+8) != getUint32(data, 16+2*12+8) {
+		t.Error("identical values not shared")
+	}
+	m2, err := decodeMLUC(data, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(m, m2); d != "" {
+		t.Errorf("round trip failed (-want +got):\n%s", d)
+	}
+
+	desc4, _ := m.EncodeDescription(Version4_4_0)
+	text4, _ := m.EncodeText(0)
+	for _, data := range [][]byte{desc4, text4} {
+		if elemType, _ := TagElementType(data); elemType != "mluc" {
+			t.Errorf("version 4: wrong element type %q", elemType)
+		}
+	}
+	text2, _ := m.EncodeText(Version2_1_0)
+	if elemType, _ := TagElementType(text2); elemType != "text" {
+		t.Errorf("version 2: wrong element type %q", elemType)
+	}
+	v2, err := m.EncodeDescription(Version2_1_0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := decodeTextDescription(v2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s != "colour" {
+		t.Errorf("version 2: wrong text %q", s)
+	}
+}
+
+func TestEncodeMLUCRoundTrip(t *testing.T) {
+	for _, m := range []MultiLocalizedUnicode{
+		{},
+		{{Language: "la", Value: "color"}},
+		{{Language: "en", Country: "us", Value: "lower case"}},
+	} {
+		data, err := m.Encode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m2, err := decodeMLUC(data, false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if d := cmp.Diff(m, m2); d != "" {
+			t.Errorf("round trip failed (-want +got):\n%s", d)
+		}
+	}
+}
+
+func TestEncodeMLUCInvalid(t *testing.T) {
+	for _, lu := range []LocalizedUnicode{
+		{Language: "deu", Country: "DE"},
+		{Language: "d", Country: "DE"},
+		{Language: "", Country: "DE"},
+		{Language: "en", Country: "USA"},
+		{Language: "en", Country: "1"},
+		{Language: "e1", Country: "US"},
+		{Language: "\000\000", Country: "US"},
+		{Language: "ü", Country: "US"},
+	} {
+		m := MultiLocalizedUnicode{lu}
+		if _, err := m.Encode(); err != errInvalidLanguage {
+			t.Errorf("%q-%q: got %v", lu.Language, lu.Country, err)
+		}
+		for _, v := range []Version{Version2_1_0, Version4_4_0} {
+			if _, err := m.EncodeDescription(v); err != errInvalidLanguage {
+				t.Errorf("%q-%q, %s: got %v", lu.Language, lu.Country, v, err)
+			}
+		}
+	}
+}
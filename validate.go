@@ -0,0 +1,88 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "fmt"
+
+// Validate checks the contents of tags which have a restricted set of
+// allowed values.  Tags which are not present are not checked.
+//
+// Tags which were removed in version 4 of the specification, like the
+// device settings tag, are not errors.  Readers may ignore them, and they
+// are common in real-world version 4 profiles.  Use ValidationWarnings to
+// find such tags.
+func (p *Profile) Validate() error {
+	if _, ok := p.TagData[DeviceSettingsTag]; ok {
+		_, err := p.DeviceSettings()
+		if err != nil {
+			return &ValidationError{Tag: DeviceSettingsTag, Reason: err.Error()}
+		}
+	}
+
+	for _, tagType := range []TagType{PerceptualRenderingIntentGamut, SaturationRenderingIntentGamut} {
+		if _, ok := p.TagData[tagType]; !ok {
+			continue
+		}
+		g, err := p.getRenderingIntentGamut(tagType)
+		if err != nil {
+			return &ValidationError{Tag: tagType, Reason: err.Error()}
+		}
+		if g != PerceptualReferenceMediumGamut {
+			return &ValidationError{Tag: tagType, Reason: "unregistered signature " + g.String()}
+		}
+	}
+	return nil
+}
+
+// ValidationWarnings returns the problems found in the profile which do
+// not make the profile invalid.  Currently, this lists the tags which were
+// removed in version 4 of the specification, if they are present in a
+// version 4 profile.  The result is nil if no problems are found.
+func (p *Profile) ValidationWarnings() []error {
+	if !p.isV4() {
+		return nil
+	}
+	var res []error
+	for _, tagType := range deprecatedTags {
+		if _, ok := p.TagData[tagType]; ok {
+			res = append(res, &ValidationWarning{Tag: tagType, Reason: "removed in version 4"})
+		}
+	}
+	return res
+}
+
+// ValidationWarning describes a problem which does not make a profile
+// invalid, but may cause problems with some readers.
+type ValidationWarning struct {
+	Tag    TagType
+	Reason string
+}
+
+func (w *ValidationWarning) Error() string {
+	return fmt.Sprintf("icc: tag %s: %s", w.Tag, w.Reason)
+}
+
+// ValidationError indicates that a profile does not conform to the ICC
+// specification.
+type ValidationError struct {
+	Tag    TagType
+	Reason string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("icc: invalid tag %s: %s", e.Tag, e.Reason)
+}
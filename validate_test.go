@@ -0,0 +1,133 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestValidateRenderingIntentGamut(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	if err := p.Validate(); err != nil {
+		t.Fatalf("empty profile: %v", err)
+	}
+
+	p.SetPerceptualRenderingIntentGamut(PerceptualReferenceMediumGamut)
+	g, err := p.PerceptualRenderingIntentGamut()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g != PerceptualReferenceMediumGamut {
+		t.Errorf("got %s, want %s", g, PerceptualReferenceMediumGamut)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("valid rig0 tag: %v", err)
+	}
+
+	p.SetSaturationRenderingIntentGamut(0x78787878)
+	if err := p.Validate(); err == nil {
+		t.Error("unregistered rig2 signature not detected")
+	}
+}
+
+func TestValidateDeviceSettings(t *testing.T) {
+	devs := DeviceSettings{
+		{
+			Platform: 0x6D736674, // "msft"
+			Combinations: [][]*DeviceSetting{
+				{
+					{ID: 0x6D736672, Values: [][]byte{{0, 0, 0, 1}, {0, 0, 0, 2}}},
+					{ID: 0x6D736D6F, Values: [][]byte{{0, 0, 0, 3}}},
+				},
+				{},
+			},
+		},
+	}
+
+	p := &Profile{Version: Version2_1_0, TagData: make(map[TagType][]byte)}
+	if err := p.SetDeviceSettings(devs); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.Validate(); err != nil {
+		t.Errorf("version 2: %v", err)
+	}
+	got, err := p.DeviceSettings()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := cmp.Diff(devs, got); d != "" {
+		t.Errorf("round trip failed (-want +got):\n%s", d)
+	}
+
+	p.Version = Version4_0_0
+	if err := p.Validate(); err != nil {
+		t.Errorf("version 4: %v", err)
+	}
+	if len(p.ValidationWarnings()) != 1 {
+		t.Error("devs tag in version 4 profile not reported")
+	}
+}
+
+func TestValidateDeprecatedTags(t *testing.T) {
+	for _, tagType := range []TagType{CRDInfoTag, NamedColorTag, ScreeningDescTag, UCRBGTag} {
+		p := &Profile{Version: Version2_1_0, TagData: make(map[TagType][]byte)}
+		p.TagData[tagType] = []byte("junk tag data")
+		if err := p.Validate(); err != nil {
+			t.Errorf("%s: version 2: %v", tagType, err)
+		}
+		if w := p.ValidationWarnings(); w != nil {
+			t.Errorf("%s: version 2: unexpected warnings %v", tagType, w)
+		}
+
+		p.Version = Version4_4_0
+		if err := p.Validate(); err != nil {
+			t.Errorf("%s: version 4: %v", tagType, err)
+		}
+		w := p.ValidationWarnings()
+		if len(w) != 1 {
+			t.Errorf("%s: version 4: got warnings %v", tagType, w)
+		} else if vw, ok := w[0].(*ValidationWarning); !ok || vw.Tag != tagType {
+			t.Errorf("%s: version 4: wrong warning %v", tagType, w[0])
+		}
+	}
+}
+
+// devsZeroSizeValues is a device settings tag which claims 2^32-1 values of
+// length zero.
+var devsZeroSizeValues = []byte("devs\x00\x00\x00\x00\x00\x00\x00\x01" +
+	"msft\x00\x00\x00\x20\x00\x00\x00\x01" +
+	"\x00\x00\x00\x14\x00\x00\x00\x01" +
+	"msfr\x00\x00\x00\x00\xff\xff\xff\xff")
+
+func TestDeviceSettingsMalformed(t *testing.T) {
+	if len(devsZeroSizeValues) != 44 {
+		t.Fatalf("wrong test data length %d", len(devsZeroSizeValues))
+	}
+	if _, err := decodeDeviceSettings(devsZeroSizeValues); err == nil {
+		t.Error("zero-length values not detected")
+	}
+
+	p := &Profile{Version: Version2_1_0, TagData: make(map[TagType][]byte)}
+	err := p.SetDeviceSettings(DeviceSettings{
+		{Combinations: [][]*DeviceSetting{{{Values: [][]byte{{1, 2}, {3}}}}}},
+	})
+	if err == nil {
+		t.Error("values of different length not detected")
+	}
+}
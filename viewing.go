@@ -0,0 +1,123 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import "errors"
+
+// ViewingConditions is the contents of the viewing conditions tag.
+type ViewingConditions struct {
+	// Illuminant gives the absolute XYZ values of the illuminant, in cd/m².
+	Illuminant XYZ
+
+	// Surround gives the absolute XYZ values of the surround, in cd/m².
+	Surround XYZ
+
+	// IlluminantType identifies the standard illuminant, using the
+	// encoding from the ICC specification (0 = unknown, 1 = D50, 2 = D65,
+	// 3 = D93, 4 = F2, 5 = D55, 6 = A, 7 = equi-power (E), 8 = F8).
+	IlluminantType uint32
+}
+
+func decodeViewingConditions(data []byte) (*ViewingConditions, error) {
+	err := checkType("view", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 36 {
+		return nil, errInvalidTagData
+	}
+	res := &ViewingConditions{
+		Illuminant:     getXYZ(data, 8),
+		Surround:       getXYZ(data, 20),
+		IlluminantType: getUint32(data, 32),
+	}
+	return res, nil
+}
+
+func (v *ViewingConditions) encode() []byte {
+	buf := make([]byte, 36)
+	copy(buf, "view")
+	putXYZ(buf, 8, v.Illuminant)
+	putXYZ(buf, 20, v.Surround)
+	putUint32(buf, 32, v.IlluminantType)
+	return buf
+}
+
+// ViewingConditions returns the contents of the viewing conditions tag.
+func (p *Profile) ViewingConditions() (*ViewingConditions, error) {
+	tag, ok := p.TagData[ViewingConditionsTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeViewingConditions(tag)
+}
+
+// SetViewingConditions sets the viewing conditions tag.
+func (p *Profile) SetViewingConditions(v *ViewingConditions) {
+	p.SetTag(ViewingConditionsTag, v.encode())
+}
+
+// CAM16Parameters derives the parameters of the CAM16 color appearance
+// model from the viewing conditions.
+//
+// The adapting luminance is taken to be 20% of the illuminant luminance,
+// corresponding to a background with Y_b = 20.  The surround is classified
+// using the ratio of the surround and illuminant luminances, as described
+// in CIE 159:2004.
+func (v *ViewingConditions) CAM16Parameters() (*CAM16Parameters, error) {
+	Lw := v.Illuminant[1]
+	if !(Lw > 0) {
+		return nil, errors.New("icc: viewing conditions without illuminant luminance")
+	}
+
+	var surround Surround
+	switch ratio := v.Surround[1] / Lw; {
+	case ratio >= 0.2:
+		surround = SurroundAverage
+	case ratio > 0:
+		surround = SurroundDim
+	default:
+		surround = SurroundDark
+	}
+
+	res := &CAM16Parameters{
+		White: XYZ{
+			v.Illuminant[0] / Lw,
+			1,
+			v.Illuminant[2] / Lw,
+		},
+		AdaptingLuminance:   Lw * 0.2,
+		BackgroundLuminance: 20,
+		Surround:            surround,
+	}
+	return res, nil
+}
+
+func getXYZ(data []byte, offset int) XYZ {
+	return XYZ{
+		getS15Fixed16(data, offset),
+		getS15Fixed16(data, offset+4),
+		getS15Fixed16(data, offset+8),
+	}
+}
+
+func putXYZ(data []byte, offset int, c XYZ) {
+	putS15Fixed16(data, offset, c[0])
+	putS15Fixed16(data, offset+4, c[1])
+	putS15Fixed16(data, offset+8, c[2])
+}
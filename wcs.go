@@ -0,0 +1,144 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// WCSProfiles contains the Windows Color System profiles embedded in the
+// private 'MS00' tag.  Each field holds the raw XML document, usually
+// encoded as UTF-16 with a byte order mark.  Missing parts are nil.
+type WCSProfiles struct {
+	ColorDeviceModel     []byte
+	ColorAppearanceModel []byte
+	GamutMapModel        []byte
+}
+
+func decodeWCSProfiles(data []byte) (*WCSProfiles, error) {
+	err := checkType("MS10", data)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(data) < 32 {
+		return nil, errInvalidTagData
+	}
+	var parts [3][]byte
+	for i := range parts {
+		offset := getUint32(data, 8+8*i)
+		length := getUint32(data, 12+8*i)
+		if length == 0 {
+			continue
+		}
+		start := uint64(offset)
+		end := start + uint64(length)
+		if start < 32 || end > uint64(len(data)) {
+			return nil, errInvalidTagData
+		}
+		parts[i] = data[start:end]
+	}
+	res := &WCSProfiles{
+		ColorDeviceModel:     parts[0],
+		ColorAppearanceModel: parts[1],
+		GamutMapModel:        parts[2],
+	}
+	return res, nil
+}
+
+func (w *WCSProfiles) encode() []byte {
+	parts := [3][]byte{w.ColorDeviceModel, w.ColorAppearanceModel, w.GamutMapModel}
+	size := 32
+	for _, part := range parts {
+		size += len(part)
+	}
+	buf := make([]byte, 32, size)
+	copy(buf, "MS10")
+	for i, part := range parts {
+		if len(part) == 0 {
+			continue
+		}
+		putUint32(buf, 8+8*i, uint32(len(buf)))
+		putUint32(buf, 12+8*i, uint32(len(part)))
+		buf = append(buf, part...)
+	}
+	return buf
+}
+
+// ColorDeviceModelXML returns the color device model profile as a string.
+func (w *WCSProfiles) ColorDeviceModelXML() string {
+	return decodeXMLText(w.ColorDeviceModel)
+}
+
+// ColorAppearanceModelXML returns the color appearance model profile as a
+// string.
+func (w *WCSProfiles) ColorAppearanceModelXML() string {
+	return decodeXMLText(w.ColorAppearanceModel)
+}
+
+// GamutMapModelXML returns the gamut map model profile as a string.
+func (w *WCSProfiles) GamutMapModelXML() string {
+	return decodeXMLText(w.GamutMapModel)
+}
+
+// decodeXMLText converts an XML document to a string.  UTF-16 data is
+// recognised by the byte order mark, everything else is assumed to be
+// UTF-8.
+func decodeXMLText(data []byte) string {
+	var s string
+	switch {
+	case len(data) >= 2 && data[0] == 0xFF && data[1] == 0xFE:
+		d16 := make([]uint16, (len(data)-2)/2)
+		for i := range d16 {
+			d16[i] = uint16(data[2+2*i]) | uint16(data[3+2*i])<<8
+		}
+		s = string(utf16.Decode(d16))
+	case len(data) >= 2 && data[0] == 0xFE && data[1] == 0xFF:
+		d16 := make([]uint16, (len(data)-2)/2)
+		for i := range d16 {
+			d16[i] = getUint16(data, 2+2*i)
+		}
+		s = string(utf16.Decode(d16))
+	case len(data) >= 3 && data[0] == 0xEF && data[1] == 0xBB && data[2] == 0xBF:
+		s = string(data[3:])
+	default:
+		s = string(data)
+	}
+	for len(s) > 0 {
+		r, size := utf8.DecodeLastRuneInString(s)
+		if r != 0 {
+			break
+		}
+		s = s[:len(s)-size]
+	}
+	return s
+}
+
+// WCSProfiles returns the contents of the Windows Color System profiles tag.
+func (p *Profile) WCSProfiles() (*WCSProfiles, error) {
+	tag, ok := p.TagData[WCSProfilesTag]
+	if !ok {
+		return nil, errMissingTag
+	}
+	return decodeWCSProfiles(tag)
+}
+
+// SetWCSProfiles sets the Windows Color System profiles tag.
+func (p *Profile) SetWCSProfiles(w *WCSProfiles) {
+	p.SetTag(WCSProfilesTag, w.encode())
+}
@@ -25,41 +25,45 @@ import (
 
 // Encode converts the profile to binary form.
 func (p *Profile) Encode() []byte {
+	return p.EncodeWithOptions(nil)
+}
+
+// EncodeOptions controls how EncodeWithOptions writes a profile.
+type EncodeOptions struct {
+	// CreationDate, if non-zero, is written to the header instead of
+	// Profile.CreationDate.
+	CreationDate time.Time
+
+	// ZeroDate causes an all-zero creation date to be written to the header.
+	// This can be used to make the output reproducible.
+	ZeroDate bool
+}
+
+// EncodeWithOptions converts the profile to binary form, using the given
+// options.  If opt is nil, the function behaves like Encode.
+//
+// The creation date is always stored in UTC.  A zero time.Time value is
+// stored as an all-zero date field.
+//
+// The profile is not modified.  In particular, p.Dirty is left unchanged;
+// use MarkClean after storing the encoded profile.
+func (p *Profile) EncodeWithOptions(opt *EncodeOptions) []byte {
+	if opt == nil {
+		opt = &EncodeOptions{}
+	}
+	creationDate := p.CreationDate
+	if opt.ZeroDate {
+		creationDate = time.Time{}
+	} else if !opt.CreationDate.IsZero() {
+		creationDate = opt.CreationDate
+	}
+
 	version := p.Version
 	if version == 0 {
 		version = currentVersion
 	}
 
-	// arrange tags in order of increasing length and merge duplicates
-	type tagInfo struct {
-		tagType   TagType
-		data      []byte
-		start     uint32
-		duplicate bool
-	}
-	var tags []tagInfo
-	for tagType, data := range p.TagData {
-		tags = append(tags, tagInfo{
-			tagType: tagType,
-			data:    data,
-		})
-	}
-	sort.Slice(tags, func(i, j int) bool {
-		if len(tags[i].data) != len(tags[j].data) {
-			return len(tags[i].data) < len(tags[j].data)
-		}
-		return bytes.Compare(tags[i].data, tags[j].data) < 0
-	})
-	pos := 128 + 4 + len(tags)*12
-	for i := range tags {
-		if i > 0 && bytes.Equal(tags[i].data, tags[i-1].data) {
-			tags[i].start = tags[i-1].start
-			tags[i].duplicate = true
-		} else {
-			tags[i].start = uint32(pos)
-			pos += (len(tags[i].data) + 3) &^ 3
-		}
-	}
+	tags, pos := p.layoutTags()
 
 	buf := make([]byte, pos)
 	putUint32(buf, 0, uint32(pos))
@@ -68,7 +72,7 @@ func (p *Profile) Encode() []byte {
 	putUint32(buf, 12, uint32(p.Class))
 	putUint32(buf, 16, uint32(p.ColorSpace))
 	putUint32(buf, 20, uint32(p.PCS))
-	putDateTime(buf, 24, p.CreationDate)
+	putDateTime(buf, 24, creationDate)
 	putUint32(buf, 36, 0x61637370) // "acsp"
 	putUint32(buf, 40, p.PrimaryPlatform)
 	putUint32(buf, 48, p.DeviceManufacturer)
@@ -100,9 +104,59 @@ func (p *Profile) Encode() []byte {
 	putUint32(buf, 44, p.Flags)
 	putUint32(buf, 64, uint32(p.RenderingIntent))
 
+	buf = append(buf, p.TrailingData...)
+
 	return buf
 }
 
+// EncodedSize returns the length of the binary representation of the
+// profile, as produced by Encode.
+func (p *Profile) EncodedSize() int {
+	_, size := p.layoutTags()
+	return size + len(p.TrailingData)
+}
+
+type tagInfo struct {
+	tagType   TagType
+	data      []byte
+	start     uint32
+	duplicate bool
+}
+
+// layoutTags determines the position of the tag data in the encoded
+// profile.  The function returns the tag table entries, in order, and the
+// total size of the encoded profile.
+func (p *Profile) layoutTags() ([]tagInfo, int) {
+	// arrange tags in order of increasing length and merge duplicates
+	tags := make([]tagInfo, 0, len(p.TagData))
+	for tagType, data := range p.TagData {
+		tags = append(tags, tagInfo{
+			tagType: tagType,
+			data:    data,
+		})
+	}
+	sort.Slice(tags, func(i, j int) bool {
+		if len(tags[i].data) != len(tags[j].data) {
+			return len(tags[i].data) < len(tags[j].data)
+		}
+		if c := bytes.Compare(tags[i].data, tags[j].data); c != 0 {
+			return c < 0
+		}
+		return tags[i].tagType < tags[j].tagType
+	})
+	pos := 128 + 4 + len(tags)*12
+	for i := range tags {
+		if i > 0 && bytes.Equal(tags[i].data, tags[i-1].data) {
+			tags[i].start = tags[i-1].start
+			tags[i].duplicate = true
+		} else {
+			tags[i].start = uint32(pos)
+			pos += (len(tags[i].data) + 3) &^ 3
+		}
+	}
+	return tags, pos
+}
+
 // This is the value for the "PCS illuminant" header field (Bytes 68 to 79).
 var d50 = []byte{
 	0x00, 0x00, 0xf6, 0xd6, 0x00, 0x01, 0x00, 0x00, 0x00, 0x00, 0xd3, 0x2d,
@@ -127,6 +181,10 @@ func putUint64(data []byte, offset int, value uint64) {
 }
 
 func putDateTime(data []byte, offset int, t time.Time) {
+	if t.IsZero() {
+		return
+	}
+	t = t.UTC()
 	year := t.Year()
 	data[offset] = byte(year >> 8)
 	data[offset+1] = byte(year)
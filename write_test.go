@@ -0,0 +1,151 @@
+// seehuhn.de/go/icc - read and write ICC profiles
+// Copyright (C) 2024  Jochen Voss <voss@seehuhn.de>
+//
+// This program is free software: you can redistribute it and/or modify
+// it under the terms of the GNU General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// This program is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+// GNU General Public License for more details.
+//
+// You should have received a copy of the GNU General Public License
+// along with this program.  If not, see <https://www.gnu.org/licenses/>.
+
+package icc
+
+import (
+	"bytes"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEncodedSize(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	check := func() {
+		t.Helper()
+		size := p.EncodedSize()
+		data := p.Encode()
+		if size != len(data) {
+			t.Errorf("EncodedSize() = %d, len(Encode()) = %d", size, len(data))
+		}
+	}
+
+	check()
+	p.TagData[0x61616161] = []byte{1, 2, 3, 4, 5}
+	check()
+	p.TagData[0x62626262] = []byte{1, 2, 3, 4, 5} // shared with "aaaa"
+	check()
+	p.TagData[0x63636363] = []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	check()
+}
+
+// TestConcurrentEncode checks that Encode does not modify the profile.
+// Run with -race to detect violations.
+func TestConcurrentEncode(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.SetTag(Copyright, []byte("text\000\000\000\000Public Domain\000"))
+	want := p.Encode()
+
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if got := p.Encode(); !bytes.Equal(got, want) {
+				t.Error("Encode results differ")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if !p.Dirty {
+		t.Error("Dirty cleared by Encode")
+	}
+}
+
+func TestFlateRoundTrip(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	p.TagData[Copyright] = []byte("text\000\000\000\000Public Domain\000")
+
+	for _, data := range [][]byte{p.EncodeFlate(), p.Encode()} {
+		q, err := Read(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(q.TagData[Copyright], p.TagData[Copyright]) {
+			t.Errorf("tag data differs after round trip")
+		}
+	}
+}
+
+func TestIsCompressed(t *testing.T) {
+	p := &Profile{TagData: make(map[TagType][]byte)}
+	if !isCompressed(p.EncodeFlate()) {
+		t.Error("compressed profile not detected")
+	}
+
+	// The size field of a 136MB profile is also a valid zlib header.
+	head := p.Encode()[:40]
+	putUint32(head, 0, 0x081D0000)
+	if isCompressed(head) {
+		t.Error("uncompressed profile detected as compressed")
+	}
+}
+
+func TestCreationDate(t *testing.T) {
+	loc := time.FixedZone("UTC+2", 2*60*60)
+	p := &Profile{
+		TagData:      make(map[TagType][]byte),
+		CreationDate: time.Date(2024, 3, 1, 1, 30, 0, 0, loc),
+	}
+	want := time.Date(2024, 2, 29, 23, 30, 0, 0, time.UTC)
+
+	q, err := Decode(p.Encode())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.CreationDate.Equal(want) || q.CreationDate.Location() != time.UTC {
+		t.Errorf("got %s, want %s", q.CreationDate, want)
+	}
+
+	data := p.EncodeWithOptions(&EncodeOptions{ZeroDate: true})
+	if !isZero(data[24:36]) {
+		t.Errorf("date field is not zero: % x", data[24:36])
+	}
+}
+
+func TestCreationDateOverride(t *testing.T) {
+	p := &Profile{
+		TagData:      make(map[TagType][]byte),
+		CreationDate: time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC),
+	}
+	override := time.Date(2024, 12, 31, 23, 59, 58, 0, time.UTC)
+
+	data := p.EncodeWithOptions(&EncodeOptions{CreationDate: override})
+	// year, month, day, hour, minute and second as big-endian uint16 values
+	want := []byte{0x07, 0xE8, 0, 12, 0, 31, 0, 23, 0, 59, 0, 58}
+	if !bytes.Equal(data[24:36], want) {
+		t.Errorf("wrong date field: % x", data[24:36])
+	}
+
+	q, err := Decode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !q.CreationDate.Equal(override) {
+		t.Errorf("got %s, want %s", q.CreationDate, override)
+	}
+	if !p.CreationDate.Equal(time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Error("profile modified by EncodeWithOptions")
+	}
+
+	// ZeroDate takes precedence
+	data = p.EncodeWithOptions(&EncodeOptions{CreationDate: override, ZeroDate: true})
+	if !isZero(data[24:36]) {
+		t.Errorf("date field is not zero: % x", data[24:36])
+	}
+}